@@ -1,6 +1,7 @@
 package main
 
 import (
+	"os"
 	"os/exec"
 	"syscall"
 
@@ -18,5 +19,9 @@ func main() {
 		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
 	}
 
+	app.App.SignalCommandCallback = func(cmd *exec.Cmd, sig os.Signal) {
+		syscall.Kill(-cmd.Process.Pid, sig.(syscall.Signal))
+	}
+
 	app.App.Run()
 }
@@ -4,19 +4,55 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/robertkrimen/otto"
 	"github.com/stackup-app/stackup/lib/app/commands"
+	"github.com/stackup-app/stackup/lib/consts"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
 	"github.com/stackup-app/stackup/lib/version"
 )
 
+const (
+	OutputFormatText = "text"
+	OutputFormatJson = "json"
+)
+
 type AppFlags struct {
 	DisplayHelp    *bool
 	DisplayVersion *bool
 	NoUpdateCheck  *bool
+	DryRun         *bool
+	Output         *string
+	Dashboard      *bool
 	ConfigFile     *string
+	Command        string
+	Args           []string
+	Vars           VarFlags
+	Json           bool
+	Lines          int
 	app            *Application
 }
 
+// VarFlags collects the values of a repeatable `--set name=value` flag.
+type VarFlags []string
+
+func (vf *VarFlags) String() string {
+	return strings.Join(*vf, ",")
+}
+
+func (vf *VarFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected name=value, got '%s'", value)
+	}
+
+	*vf = append(*vf, value)
+
+	return nil
+}
+
 func (af *AppFlags) Parse() {
 	flag.Parse()
 
@@ -24,9 +60,59 @@ func (af *AppFlags) Parse() {
 		af.app.ConfigFilename = *af.ConfigFile
 	}
 
+	af.Command = flag.Arg(0)
+
+	if flag.NArg() > 1 {
+		af.Args = af.parseCommandArgs(flag.Args()[1:])
+	}
+
 	af.handle()
 }
 
+// parseCommandArgs parses the flags of a subcommand, which may appear before or after its arguments, and
+// returns the remaining arguments.
+func (af *AppFlags) parseCommandArgs(args []string) []string {
+	result := []string{}
+
+	fs := flag.NewFlagSet(af.Command, flag.ExitOnError)
+	fs.Var(&af.Vars, "set", "Set an application variable, as name=value (may be repeated)")
+	fs.BoolVar(&af.Json, "json", false, "Display output as JSON")
+	fs.IntVar(&af.Lines, "lines", consts.DEFAULT_LOG_LINES, "The number of log lines to display")
+
+	for len(args) > 0 {
+		fs.Parse(args)
+		args = fs.Args()
+
+		if len(args) > 0 {
+			result = append(result, args[0])
+			args = args[1:]
+		}
+	}
+
+	return result
+}
+
+// IsJsonOutput returns true if lifecycle events should be written to stdout as JSON, one event per line.
+func (af *AppFlags) IsJsonOutput() bool {
+	return af.Output != nil && *af.Output == OutputFormatJson
+}
+
+// IsDashboard returns true if the dashboard should be displayed while the workflow is running.
+func (af *AppFlags) IsDashboard() bool {
+	return af.Dashboard != nil && *af.Dashboard
+}
+
+// IsCommand returns true if the subcommand given on the command line is one of `names`.
+func (af *AppFlags) IsCommand(names ...string) bool {
+	for _, name := range names {
+		if af.Command == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (af *AppFlags) handle() {
 	if *af.DisplayHelp {
 		flag.Usage()
@@ -38,8 +124,49 @@ func (af *AppFlags) handle() {
 		os.Exit(0)
 	}
 
-	if len(os.Args) > 1 && os.Args[1] == "init" {
+	if af.Output != nil && *af.Output != OutputFormatText && *af.Output != OutputFormatJson {
+		fmt.Fprintln(os.Stderr, messages.InvalidOutputFormat(*af.Output))
+		os.Exit(2)
+	}
+
+	// the dashboard is drawn on the terminal, so it cannot be combined with json output
+	if af.IsDashboard() && (af.IsJsonOutput() || !canDisplayDashboard()) {
+		support.WarningMessage(messages.DashboardRequiresTerminal())
+		*af.Dashboard = false
+	}
+
+	// keep stdout free for json output
+	if af.Json || af.IsJsonOutput() {
+		support.SetMessageOutput(os.Stderr)
+	}
+
+	if af.IsJsonOutput() {
+		events.Subscribe(events.NewJsonHandler(os.Stdout))
+	}
+
+	if af.IsCommand("init") {
 		commands.CreateNewConfigFile(af.app.Gateway)
 		os.Exit(0)
 	}
+
+	// the schema is generated from the configuration structs, so no configuration file is needed
+	if af.IsCommand("schema") {
+		if err := writeSchema(os.Stdout, strings.Join(af.Args, " ")); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	// these commands control an instance that is already running, so the configuration is not loaded
+	if af.IsCommand("status", "restart", "logs") {
+		os.Exit(af.app.runControlCommand(af.Command, af.Args))
+	}
+
+	for _, v := range af.Vars {
+		name, value, _ := strings.Cut(v, "=")
+		jsValue, _ := otto.ToValue(value)
+		af.app.Vars.Store(strings.TrimSpace(name), jsValue)
+	}
 }
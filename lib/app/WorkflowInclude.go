@@ -81,14 +81,17 @@ func (wi *WorkflowInclude) setDefaults() {
 	wi.ValidationState = ChecksumVerificationStateNotVerified
 }
 
-func (wi *WorkflowInclude) loadedStatusText() string {
-	result := "fetched"
-
+// returns "cached" if the include was loaded from the cache, otherwise "fetched".
+func (wi *WorkflowInclude) loadedSourceText() string {
 	if wi.FromCache {
-		result = "cached"
+		return "cached"
 	}
 
-	return fmt.Sprintf("%s, %s", result, wi.ValidationState.String())
+	return "fetched"
+}
+
+func (wi *WorkflowInclude) loadedStatusText() string {
+	return fmt.Sprintf("%s, %s", wi.loadedSourceText(), wi.ValidationState.String())
 }
 
 func (wi *WorkflowInclude) setLoadedFromCache(loaded bool, data *cache.CacheEntry) {
@@ -78,6 +78,27 @@ func (wp *WorkflowPrecondition) Run() bool {
 	return result
 }
 
+// evaluateCheck returns the result of the check script, evaluating the result as well if it is a script.
+func (wp *WorkflowPrecondition) evaluateCheck() bool {
+	if wp.Check == "" {
+		return true
+	}
+
+	scriptResult := wp.JsEngine.Evaluate(wp.Check)
+	if scriptResult == nil {
+		return false
+	}
+
+	resultType, resultValue, _ := wp.JsEngine.ResultType(scriptResult)
+
+	if resultType == reflect.String && resultValue != "" {
+		result, _ := wp.JsEngine.Evaluate(resultValue.(string)).(bool)
+		return result
+	}
+
+	return resultType != reflect.Bool || resultValue == true
+}
+
 func (wp *WorkflowPrecondition) handleOnFail() bool {
 	if len(wp.OnFail) == 0 {
 		return false
@@ -1,13 +1,26 @@
 package app
 
 import (
+	"sync"
+
 	lls "github.com/emirpasic/gods/stacks/linkedliststack"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
 )
 
 type WorkflowState struct {
 	CurrentTask *Task
 	Stack       *lls.Stack
 	History     *lls.Stack
+	Completed   *sync.Map
+	running     map[string]*dependencyRun
+	runLock     *sync.Mutex
+}
+
+// dependencyRun is a run of a dependency that other dependents wait for instead of running it again.
+type dependencyRun struct {
+	done    chan struct{}
+	success bool
 }
 
 type CleanupCallback = func()
@@ -18,6 +31,9 @@ func NewWorkflowState() WorkflowState {
 		CurrentTask: nil,
 		Stack:       lls.New(),
 		History:     lls.New(),
+		Completed:   &sync.Map{},
+		running:     map[string]*dependencyRun{},
+		runLock:     &sync.Mutex{},
 	}
 }
 
@@ -45,3 +61,71 @@ func (ws *WorkflowState) SetCurrent(task *Task) CleanupCallback {
 		}
 	}
 }
+
+// records whether or not the most recent run of `task` completed successfully.
+func (ws *WorkflowState) SetCompleted(task *Task, success bool) {
+	if ws.Completed != nil {
+		ws.Completed.Store(task.Uuid, success)
+	}
+}
+
+// returns true if the most recent run of `task` completed successfully.
+func (ws *WorkflowState) HasCompleted(task *Task) bool {
+	if ws.Completed == nil {
+		return false
+	}
+
+	value, found := ws.Completed.Load(task.Uuid)
+
+	return found && value.(bool)
+}
+
+// runs `task` without its dependencies unless it has already completed successfully, and returns false if
+// it failed; a task that is skipped because of its platforms or conditions does not fail.  If the task is
+// already being run as a dependency, waits for that run to finish instead of running it again, so that a
+// task shared by several dependents only runs once.  Unrelated tasks are run at the same time.  A task that
+// is required by one of its own hooks fails instead of waiting for itself.
+func (ws *WorkflowState) runOnce(task *Task) bool {
+	if ws.HasCompleted(task) {
+		return true
+	}
+
+	if task.runningHooks.Load() {
+		support.FailureMessageWithXMark(messages.DependencySkippedRecursion(task.GetDisplayName()))
+		return false
+	}
+
+	if ws.runLock == nil {
+		return task.run() != runFailed
+	}
+
+	ws.runLock.Lock()
+
+	if ws.HasCompleted(task) {
+		ws.runLock.Unlock()
+		return true
+	}
+
+	if current, found := ws.running[task.Uuid]; found {
+		ws.runLock.Unlock()
+		<-current.done
+
+		return current.success
+	}
+
+	current := &dependencyRun{done: make(chan struct{})}
+	ws.running[task.Uuid] = current
+	ws.runLock.Unlock()
+
+	defer func() {
+		ws.runLock.Lock()
+		delete(ws.running, task.Uuid)
+		ws.runLock.Unlock()
+
+		close(current.done)
+	}()
+
+	current.success = task.run() != runFailed
+
+	return current.success
+}
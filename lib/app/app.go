@@ -3,12 +3,13 @@ package app
 import (
 	"flag"
 	"fmt"
+	"net"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/eiannone/keyboard"
 	"github.com/joho/godotenv"
@@ -17,6 +18,7 @@ import (
 	"github.com/stackup-app/stackup/lib/consts"
 	"github.com/stackup-app/stackup/lib/debug"
 	"github.com/stackup-app/stackup/lib/downloader"
+	"github.com/stackup-app/stackup/lib/events"
 	"github.com/stackup-app/stackup/lib/gateway"
 	"github.com/stackup-app/stackup/lib/messages"
 	"github.com/stackup-app/stackup/lib/scripting"
@@ -27,36 +29,61 @@ import (
 	"github.com/stackup-app/stackup/lib/updater"
 	"github.com/stackup-app/stackup/lib/utils"
 	"github.com/stackup-app/stackup/lib/version"
+	"github.com/stackup-app/stackup/lib/watcher"
 	"gopkg.in/yaml.v2"
 )
 
 var App *Application
 
 type Application struct {
-	Workflow            *StackupWorkflow
-	JsEngine            *scripting.JavaScriptEngine
-	cronEngine          *cron.Cron
-	ProcessMap          *sync.Map
-	Vars                *sync.Map
-	flags               AppFlags
-	CmdStartCallback    types.CommandCallback
-	KillCommandCallback types.CommandCallback
-	ConfigFilename      string
-	Gateway             *gateway.Gateway
-	Analytics           *telemetry.Telemetry
+	Workflow              *StackupWorkflow
+	JsEngine              *scripting.JavaScriptEngine
+	cronEngine            *cron.Cron
+	ProcessMap            *sync.Map
+	Supervisor            *Supervisor
+	Vars                  *sync.Map
+	flags                 AppFlags
+	CmdStartCallback      types.CommandCallback
+	KillCommandCallback   types.CommandCallback
+	SignalCommandCallback types.SignalCommandCallback
+	ConfigFilename        string
+	Gateway               *gateway.Gateway
+	Analytics             *telemetry.Telemetry
+	actions               chan func()
+	errorHook             *errorHook
+	controlListener       net.Listener
+	dashboard             *dashboard
+	scheduledTasks        map[cron.EntryID]*ScheduledTask
+	scheduleLock          sync.Mutex
+	configWatcher         *watcher.Watcher
+	fileWatchers          []*watcher.Watcher
+	notificationRules     *notificationRules
+	notificationLock      sync.Mutex
+	workflowLock          sync.RWMutex
 	// types.AppInterface
 }
 
+// the command-line flags can only be registered once, but more than one application can be created
+var registerFlags = sync.OnceValue(func() AppFlags {
+	return AppFlags{
+		DisplayHelp:    flag.Bool("help", false, "Display help"),
+		DisplayVersion: flag.Bool("version", false, "Display version"),
+		NoUpdateCheck:  flag.Bool("no-update-check", false, "Disable update check"),
+		DryRun:         flag.Bool("dry-run", false, "Display what would be run without running any commands"),
+		Output:         flag.String("output", OutputFormatText, "Output format, either 'text' or 'json'"),
+		Dashboard:      flag.Bool("dashboard", false, "Display a full-screen dashboard of servers, scheduled tasks and task history"),
+		ConfigFile:     flag.String("config", "", "Load a specific config file"),
+	}
+})
+
 func NewApplication() *Application {
 	result := &Application{
-		ProcessMap: &sync.Map{},
-		Vars:       &sync.Map{},
-		flags: AppFlags{
-			DisplayHelp:    flag.Bool("help", false, "Display help"),
-			DisplayVersion: flag.Bool("version", false, "Display version"),
-			NoUpdateCheck:  flag.Bool("no-update-check", false, "Disable update check"),
-			ConfigFile:     flag.String("config", "", "Load a specific config file"),
-		},
+		ProcessMap:     &sync.Map{},
+		Supervisor:     NewSupervisor(),
+		Vars:           &sync.Map{},
+		actions:        make(chan func()),
+		scheduledTasks: map[cron.EntryID]*ScheduledTask{},
+		flags:          registerFlags(),
 		ConfigFilename: support.FindExistingFile([]string{"stackup.dist.yaml", "stackup.yaml"}, "stackup.yaml"),
 		Gateway:        gateway.New(nil),
 		cronEngine:     cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
@@ -97,21 +124,23 @@ func (app *Application) GetEnviron() []string {
 	return os.Environ()
 }
 
-func (a *Application) loadWorkflowFile(filename string, wf *StackupWorkflow) {
+// loadWorkflowFile parses the configuration file `filename` into `wf`.  Errors are also reported with their
+// location when the workflow is validated.
+func (a *Application) loadWorkflowFile(filename string, wf *StackupWorkflow) error {
 	wf.ExitAppFunc = a.exitApp
 	wf.Gateway = a.Gateway
 	wf.ProcessMap = a.ProcessMap
 	wf.CommandStartCb = a.CmdStartCallback
+	wf.KillCommandCb = a.KillCommandCallback
 
 	contents, err := os.ReadFile(filename)
 	if err != nil {
-		return
+		return err
 	}
 
 	err = yaml.Unmarshal(contents, wf)
 	if err != nil {
-		fmt.Printf("error loading configuration file: %v", err)
-		return
+		return err
 	}
 
 	wf.State = NewWorkflowState()
@@ -120,6 +149,24 @@ func (a *Application) loadWorkflowFile(filename string, wf *StackupWorkflow) {
 	if !wf.Debug {
 		wf.Debug = os.Getenv("DEBUG") == "true" || os.Getenv("DEBUG") == "1"
 	}
+
+	return nil
+}
+
+// currentWorkflow returns the running workflow.  The workflow is replaced on the event loop when it is
+// reloaded, so code that runs on other goroutines must use this instead of reading `Workflow` directly.
+func (a *Application) currentWorkflow() *StackupWorkflow {
+	a.workflowLock.RLock()
+	defer a.workflowLock.RUnlock()
+
+	return a.Workflow
+}
+
+func (a *Application) setWorkflow(workflow *StackupWorkflow) {
+	a.workflowLock.Lock()
+	defer a.workflowLock.Unlock()
+
+	a.Workflow = workflow
 }
 
 // parse command-line flags, load the workflow file, load .env files,
@@ -128,16 +175,33 @@ func (a *Application) Initialize() {
 	utils.EnsureConfigDirExists(utils.GetDefaultConfigurationBasePath("~", "."), consts.APP_CONFIG_PATH_BASE_NAME)
 	a.flags.Parse()
 
-	a.loadWorkflowFile(a.ConfigFilename, a.Workflow)
+	if err := a.loadWorkflowFile(a.ConfigFilename, a.Workflow); err != nil {
+		a.exitWithLoadError(err)
+	}
+
+	a.Workflow.JsonOutput = a.flags.IsJsonOutput()
 	godotenv.Load(a.Workflow.Settings.DotEnvFiles...)
 	debug.Dbg.SetEnabled(a.Workflow.Debug)
 
+	// notifications are sent from the start, so that problems loading includes are reported, but not by
+	// commands that only inspect the configuration
+	if !a.flags.IsCommand("list", "validate") && !*a.flags.DryRun {
+		a.StartNotificationRules()
+	}
+
 	a.JsEngine = scripting.CreateNewJavascriptEngine(a)
+	a.JsEngine.DryRun = *a.flags.DryRun
 	a.Analytics = telemetry.New(a.Workflow.Settings.AnonymousStatistics, a.Gateway)
 	a.Gateway.Initialize(a.Workflow.Settings, a.JsEngine.AsContract(), nil)
 	a.initializeCache()
 	a.Workflow.Initialize(a.JsEngine, a.GetConfigurationPath())
 	a.JsEngine.Initialize()
+	a.validateWorkflow()
+
+	// commands that only inspect the configuration don't need to check for updates
+	if a.flags.IsCommand("list") || *a.flags.DryRun {
+		return
+	}
 
 	a.Analytics.EventOnly("app.start")
 	a.checkForApplicationUpdates(!*a.flags.NoUpdateCheck)
@@ -145,6 +209,54 @@ func (a *Application) Initialize() {
 	downloader.New(a.Gateway).Download(consts.APP_ICON_URL, a.GetApplicationIconPath())
 }
 
+// exitWithLoadError reports why the configuration file could not be loaded, with the location of each
+// problem when it is known, and exits before anything that uses the configuration is initialized.
+func (a *Application) exitWithLoadError(err error) {
+	failed := 0
+
+	for _, e := range a.ValidateWorkflow() {
+		if !e.Warning {
+			support.FailureMessageWithXMark(e.Error())
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		support.FailureMessageWithXMark(err.Error())
+		failed++
+	}
+
+	support.StatusMessageLine(messages.ConfigurationInvalid(failed), true)
+	os.Exit(1)
+}
+
+// report configuration errors before anything is run.  When running the `validate` command, the
+// application exits after reporting the result.
+func (a *Application) validateWorkflow() {
+	errs := a.ValidateWorkflow()
+	failed := 0
+
+	for _, err := range errs {
+		if err.Warning && !a.flags.IsCommand("validate") {
+			support.WarningMessage(err.Error())
+			continue
+		}
+
+		support.FailureMessageWithXMark(err.Error())
+		failed++
+	}
+
+	if failed > 0 {
+		support.StatusMessageLine(messages.ConfigurationInvalid(failed), true)
+		os.Exit(1)
+	}
+
+	if a.flags.IsCommand("validate") {
+		support.SuccessMessageWithCheck(messages.ConfigurationValid(a.ConfigFilename))
+		os.Exit(0)
+	}
+}
+
 func (a *Application) initializeCache() {
 	a.Workflow.Cache = cache.New("stackup", a.GetConfigurationPath(), a.Workflow.Settings.Cache.TtlMinutes)
 	a.Gateway.Cache = a.Workflow.Cache
@@ -177,10 +289,16 @@ func (a *Application) hookKeyboard() {
 }
 
 func (a *Application) exitApp() {
+	a.closeDashboard()
+	a.stopWatchingConfiguration()
+	a.stopFileWatchers()
+	events.Emit(events.Event{Type: events.WorkflowStopping})
+	a.stopControlServer()
 	a.cronEngine.Stop()
 	a.stopServerProcesses()
 	support.StatusMessageLine("Running shutdown tasks...", true)
 	a.runShutdownTasks()
+	a.runWorkflowHook("on-shutdown", a.Workflow.Hooks.OnShutdown)
 
 	for _, uid := range a.Workflow.State.History.Values() {
 		task := a.Workflow.FindTaskByUuid(uid.(string))
@@ -192,6 +310,7 @@ func (a *Application) exitApp() {
 		support.StatusMessageLine("[task history] task: "+task.GetDisplayName()+" ("+runs+")", true)
 	}
 
+	a.StopNotificationRules()
 	os.Exit(1)
 }
 
@@ -209,43 +328,78 @@ func (a *Application) createScheduledTasks() {
 			continue
 		}
 
-		cron := def.Cron
-		taskId := def.TaskId()
-
-		a.cronEngine.AddFunc(cron, func() {
-			task, found := a.Workflow.GetTaskById(taskId)
-			if found {
-				task.RunSync()
-			}
-		})
+		a.addScheduledTask(def)
 	}
 
 	a.cronEngine.Start()
 	support.PrintCheckMarkLine()
 }
 
-func (a *Application) stopServerProcesses() {
-	a.ProcessMap.Range(func(key any, value any) bool {
-		t := a.Workflow.FindTaskByUuid(key.(string))
-		if t != nil {
-			support.StatusMessage("Stopping "+t.GetDisplayName()+"...", false)
-		}
+// addScheduledTask adds a cron entry that runs the scheduled task's task.  The task is found when the
+// entry runs, so that the current definition of the task is used after the workflow is reloaded.  Scheduled
+// tasks with an invalid cron expression are reported and not added.
+func (a *Application) addScheduledTask(def *ScheduledTask) {
+	taskId := def.TaskId()
 
-		if value != nil {
-			a.KillCommandCallback(value.(*exec.Cmd))
+	id, err := a.cronEngine.AddFunc(def.Cron, func() {
+		task, found := a.currentWorkflow().GetTaskById(taskId)
+		if found {
+			task.RunSync()
 		}
+	})
 
-		support.PrintCheckMarkLine()
+	if err != nil {
+		support.WarningMessage(messages.ScheduledTaskNotCreated(taskId, messages.ValidationInvalidCron(def.Cron, err.Error())))
+		return
+	}
 
-		return true
-	})
+	a.scheduleLock.Lock()
+	defer a.scheduleLock.Unlock()
+
+	a.scheduledTasks[id] = def
+}
+
+func (a *Application) removeScheduledTask(id cron.EntryID) {
+	a.cronEngine.Remove(id)
+
+	a.scheduleLock.Lock()
+	defer a.scheduleLock.Unlock()
+
+	delete(a.scheduledTasks, id)
 }
 
+// stops the server processes in reverse start order, giving each one a chance to exit cleanly
+func (a *Application) stopServerProcesses() {
+	a.Supervisor.StopAll(a.SignalCommandCallback, a.KillCommandCallback)
+}
+
+// openDashboard displays the dashboard if it was requested, or displays output as usual if it cannot be
+// displayed.
+func (a *Application) openDashboard() {
+	if a.dashboard == nil {
+		return
+	}
+
+	if err := a.dashboard.open(); err != nil {
+		support.WarningMessage(messages.DashboardNotOpened(err.Error()))
+		a.closeDashboard()
+		a.Workflow.CaptureOutput = false
+	}
+}
+
+func (a *Application) closeDashboard() {
+	if a.dashboard != nil {
+		a.dashboard.close()
+	}
+}
+
+// runEventLoop runs the actions requested through the control API, the dashboard and file watchers until the
+// application exits.
 func (a *Application) runEventLoop() {
 	support.StatusMessageLine("Running event loop...", true)
 
-	for {
-		utils.WaitForStartOfNextMinute()
+	for action := range a.actions {
+		action()
 	}
 }
 
@@ -254,6 +408,11 @@ func (a *Application) runTaskReferences(refs []*TaskReference) {
 		def.Workflow = a.Workflow
 		def.JsEngine = a.JsEngine
 
+		if def.IsParallel() {
+			a.runParallelTaskReferences(def.Parallel)
+			continue
+		}
+
 		task, found := a.Workflow.GetTaskById(def.TaskId())
 		if !found {
 			support.SkippedMessageWithSymbol(messages.TaskNotFound(def.TaskId()))
@@ -274,10 +433,12 @@ func (a *Application) runShutdownTasks() {
 	a.runTaskReferences(a.Workflow.Shutdown)
 }
 
-func (a *Application) runServerTasks() {
+// runServerTasks starts each server in order.  If a server is not ready before its ready probe times out,
+// the remaining servers are not started and false is returned.
+func (a *Application) runServerTasks() bool {
 	support.StatusMessageLine("Starting server processes...", true)
 
-	for _, def := range a.Workflow.Servers {
+	for i, def := range a.Workflow.Servers {
 		task, found := a.Workflow.GetTaskById(def.TaskId())
 
 		if !found {
@@ -285,19 +446,47 @@ func (a *Application) runServerTasks() {
 			continue
 		}
 
-		task.RunAsync()
+		server := task.RunAsync()
+		if server == nil {
+			continue
+		}
+
+		a.Supervisor.Supervise(server)
+
+		if !server.WaitUntilReady() {
+			a.skipServerTasks(a.Workflow.Servers[i+1:], task.GetDisplayName())
+			return false
+		}
 	}
+
+	return true
 }
 
-func (a Application) runPreconditions() {
+// skipServerTasks reports that each of `defs` was not started because the server `notReady` is not ready.
+func (a *Application) skipServerTasks(defs []*TaskReference, notReady string) {
+	for _, def := range defs {
+		name := def.TaskId()
+		if task, found := a.Workflow.GetTaskById(name); found {
+			name = task.GetDisplayName()
+		}
+
+		support.SkippedMessageWithSymbol(messages.ServerNotStartedNotReady(name, notReady))
+	}
+}
+
+func (a *Application) runPreconditions() {
 	support.StatusMessageLine("Running precondition checks...", true)
 
 	for _, c := range a.Workflow.Preconditions {
 		if !c.Run() {
 			support.FailureMessageWithXMark(c.Name)
+			events.Emit(events.Event{Type: events.PreconditionFailed, Name: c.Name})
+			a.runWorkflowHook("on-error", a.Workflow.Hooks.OnError)
+			a.StopNotificationRules()
 			os.Exit(1)
 		}
 		support.SuccessMessageWithCheck(c.Name)
+		events.Emit(events.Event{Type: events.PreconditionPassed, Name: c.Name})
 	}
 }
 
@@ -330,18 +519,97 @@ func (a *Application) runInitScript() {
 	a.JsEngine.Evaluate(a.Workflow.Init)
 }
 
+// runs a single task and its dependencies after running the init script and preconditions, without starting
+// any servers or scheduled tasks.  Returns the exit code of the task.
+func (a *Application) runTaskCommand(args []string) int {
+	if len(args) == 0 {
+		support.FailureMessageWithXMark(messages.RunCommandUsage())
+		return 2
+	}
+
+	task, found := a.Workflow.GetTaskById(args[0])
+	if !found {
+		support.FailureMessageWithXMark(messages.TaskNotFound(args[0]))
+		return 1
+	}
+
+	a.runInitScript()
+	a.runPreconditions()
+
+	if !task.runDependencies() {
+		return 1
+	}
+
+	if task.run() == runSkipped {
+		support.FailureMessageWithXMark(messages.RunCommandTaskSkipped(task.GetDisplayName()))
+		return consts.RUN_SKIPPED_EXIT_CODE
+	}
+
+	return task.ExitCode()
+}
+
 func (a *Application) Run() {
 	a.Initialize()
+
+	if a.flags.IsCommand("run") {
+		code := a.runTaskCommand(a.flags.Args)
+		a.Workflow.Cache.Cleanup(false)
+		a.StopNotificationRules()
+		os.Exit(code)
+	}
+
+	if a.flags.IsCommand("list") {
+		a.listTasks(os.Stdout, a.flags.Json)
+		a.Workflow.Cache.Cleanup(false)
+		os.Exit(0)
+	}
+
+	if *a.flags.DryRun {
+		a.PrintPlan(os.Stdout, time.Now())
+		a.Workflow.Cache.Cleanup(false)
+		os.Exit(0)
+	}
+
 	defer a.Workflow.Cache.Cleanup(false)
 
 	a.hookSignals()
-	a.hookKeyboard()
+
+	// the dashboard handles key presses itself once it is displayed
+	if a.flags.IsDashboard() {
+		a.dashboard = newDashboard(a, os.Stdout)
+	} else {
+		a.hookKeyboard()
+	}
+
+	events.Emit(events.Event{Type: events.WorkflowStarted, Name: a.Workflow.Name})
+	a.watchForErrors()
 
 	a.runInitScript()
+	a.runWorkflowHook("on-start", a.Workflow.Hooks.OnStart)
 	a.runPreconditions()
 	a.runStartupTasks()
-	a.runServerTasks()
+	a.errorHook.runPending(false)
+
+	// the output of servers and tasks that run from now on is displayed by the dashboard
+	a.Workflow.CaptureOutput = a.dashboard != nil
+
+	serversReady := a.runServerTasks()
+	a.errorHook.runPending(false)
 	a.createScheduledTasks()
+	a.createFileWatchers()
+	a.startControlServer()
+	a.watchConfiguration()
+
+	if serversReady {
+		events.Emit(events.Event{Type: events.WorkflowReady, Name: a.Workflow.Name})
+		a.runWorkflowHook("on-ready", a.Workflow.Hooks.OnReady)
+	} else {
+		support.WarningMessage(messages.WorkflowNotReady())
+	}
+
+	a.openDashboard()
 
+	// failures from now on run the on-error hook on the event loop
+	a.errorHook.runPending(true)
 	a.runEventLoop()
 }
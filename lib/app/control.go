@@ -0,0 +1,328 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/consts"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+)
+
+// ServerStatus describes a server process of a running instance.
+type ServerStatus struct {
+	Id        string      `json:"id"`
+	Name      string      `json:"name"`
+	State     ServerState `json:"state"`
+	Pid       int         `json:"pid,omitempty"`
+	Restarts  int         `json:"restarts"`
+	StartedAt time.Time   `json:"started-at"`
+	ExitCode  int         `json:"exit-code"`
+}
+
+// TaskStatus describes a task of a running instance.  `State` is "pending" if the task has not run, the
+// result of its most recent run ("succeeded" or "failed"), or "started" for server tasks.
+type TaskStatus struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Runs     int    `json:"runs"`
+	ExitCode int    `json:"exit-code"`
+}
+
+// WorkflowStatus describes a running instance, as returned by the control API.
+type WorkflowStatus struct {
+	Name    string         `json:"name"`
+	Pid     int            `json:"pid"`
+	Servers []ServerStatus `json:"servers"`
+	Tasks   []TaskStatus   `json:"tasks"`
+}
+
+type controlResponse struct {
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type logsResponse struct {
+	Lines []string `json:"lines"`
+}
+
+// ControlSocketPath returns the path of the unix socket that the control API listens on.  Each
+// configuration file has its own socket, so several instances can run at once for different projects.
+func (a *Application) ControlSocketPath() string {
+	filename, err := filepath.Abs(a.ConfigFilename)
+	if err != nil {
+		filename = a.ConfigFilename
+	}
+
+	hash := sha256.Sum256([]byte(filename))
+
+	return filepath.Join(a.GetConfigurationPath(), "control-"+hex.EncodeToString(hash[:])[:12]+".sock")
+}
+
+// startControlServer starts the control API, which only accepts connections on a unix socket that is
+// accessible to the current user.
+func (a *Application) startControlServer() {
+	path := a.ControlSocketPath()
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		support.WarningMessage(messages.ControlApiAlreadyRunning(path))
+		return
+	}
+
+	// a socket file left behind by an instance that did not exit cleanly
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		support.WarningMessage(messages.ControlApiNotStarted(err.Error()))
+		return
+	}
+
+	os.Chmod(path, 0600)
+	a.controlListener = listener
+
+	go http.Serve(listener, a.ControlHandler())
+}
+
+// stopControlServer stops the control API and removes its socket file.
+func (a *Application) stopControlServer() {
+	if a.controlListener != nil {
+		a.controlListener.Close()
+	}
+}
+
+// ControlHandler returns the handler for requests to the control API.
+func (a *Application) ControlHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /status", a.handleStatus)
+	mux.HandleFunc("POST /servers/{id}/restart", a.handleServerRestart)
+	mux.HandleFunc("POST /servers/{id}/stop", a.handleServerStop)
+	mux.HandleFunc("POST /tasks/{id}/run", a.handleTaskRun)
+	mux.HandleFunc("GET /tasks/{id}/logs", a.handleTaskLogs)
+	mux.HandleFunc("POST /shutdown", a.handleShutdown)
+
+	return mux
+}
+
+// runOnEventLoop runs `action` on the main goroutine and waits for it to complete.  Anything that evaluates
+// scripts must run there, since the javascript engine cannot be used from multiple goroutines at once.
+func (a *Application) runOnEventLoop(action func()) {
+	done := make(chan struct{})
+
+	a.actions <- func() {
+		defer close(done)
+		action()
+	}
+
+	<-done
+}
+
+func writeJson(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeControlError(w http.ResponseWriter, status int, message string) {
+	writeJson(w, status, controlResponse{Error: message})
+}
+
+// GetStatus returns the state of each server process and task.
+func (a *Application) GetStatus() WorkflowStatus {
+	workflow := a.currentWorkflow()
+	result := WorkflowStatus{
+		Name:    workflow.Name,
+		Pid:     os.Getpid(),
+		Servers: []ServerStatus{},
+		Tasks:   []TaskStatus{},
+	}
+
+	a.Supervisor.lock.Lock()
+	servers := append([]*ServerProcess{}, a.Supervisor.Servers...)
+	a.Supervisor.lock.Unlock()
+
+	for _, server := range servers {
+		result.Servers = append(result.Servers, server.getStatus())
+	}
+
+	for _, task := range workflow.Tasks {
+		state := "pending"
+		if value, found := workflow.State.Completed.Load(task.Uuid); found && value.(bool) {
+			state = "succeeded"
+		} else if found {
+			state = "failed"
+		} else if task.RunCount > 0 {
+			state = "started"
+		}
+
+		result.Tasks = append(result.Tasks, TaskStatus{
+			Id:       task.Id,
+			Name:     task.GetDisplayName(),
+			State:    state,
+			Runs:     task.RunCount,
+			ExitCode: task.ExitCode(),
+		})
+	}
+
+	return result
+}
+
+func (sp *ServerProcess) getStatus() ServerStatus {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	result := ServerStatus{
+		Id:        sp.Task.Id,
+		Name:      sp.Task.GetDisplayName(),
+		State:     sp.State,
+		Restarts:  sp.Restarts,
+		StartedAt: sp.StartedAt,
+		ExitCode:  sp.ExitCode,
+	}
+
+	if sp.State == ServerRunning && sp.Cmd != nil && sp.Cmd.Process != nil {
+		result.Pid = sp.Cmd.Process.Pid
+	}
+
+	return result
+}
+
+func (a *Application) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, a.GetStatus())
+}
+
+func (a *Application) handleServerRestart(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	server, found := a.Supervisor.FindServer(id)
+	if !found {
+		writeControlError(w, http.StatusNotFound, messages.ServerNotFound(id))
+		return
+	}
+
+	message, err := a.restartServerProcess(server)
+	if err != nil {
+		writeControlError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJson(w, http.StatusOK, controlResponse{Message: message})
+}
+
+// restartServerProcess restarts `server`, or starts it again from the beginning if it is no longer running,
+// and returns a message describing what was done.
+func (a *Application) restartServerProcess(server *ServerProcess) (string, error) {
+	return a.restartServerUsing(server, a.startServerProcess)
+}
+
+// restartServerUsing restarts `server`, and calls `start` to start it again if it is no longer running.
+func (a *Application) restartServerUsing(server *ServerProcess, start func(*ServerProcess) error) (string, error) {
+	name := server.Task.GetDisplayName()
+
+	if a.Supervisor.RestartServer(server, a.SignalCommandCallback, a.KillCommandCallback) {
+		return messages.ServerRestartRequested(name), nil
+	}
+
+	if server.IsActive() {
+		return messages.ServerAlreadyRestarting(name), nil
+	}
+
+	if err := start(server); err != nil {
+		return "", err
+	}
+
+	return messages.ServerRestartRequested(name), nil
+}
+
+// startServerProcess runs the task of a server that is no longer running on the event loop, replacing its
+// process.
+func (a *Application) startServerProcess(server *ServerProcess) error {
+	var err error
+
+	a.runOnEventLoop(func() {
+		err = a.startServerOnLoop(server)
+	})
+
+	return err
+}
+
+// startServerOnLoop runs the task of a server that is no longer running, replacing its process.  It must be
+// called from the main goroutine.
+func (a *Application) startServerOnLoop(server *ServerProcess) error {
+	replacement := server.Task.RunAsync()
+	if replacement == nil {
+		return errors.New(messages.ServerNotStarted(server.Task.GetDisplayName()))
+	}
+
+	a.Supervisor.Supervise(replacement)
+
+	return nil
+}
+
+func (a *Application) handleServerStop(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	server, found := a.Supervisor.FindServer(id)
+	if !found {
+		writeControlError(w, http.StatusNotFound, messages.ServerNotFound(id))
+		return
+	}
+
+	a.Supervisor.StopServer(server, a.SignalCommandCallback, a.KillCommandCallback)
+
+	writeJson(w, http.StatusOK, controlResponse{Message: messages.ServerStopping(server.Task.GetDisplayName())})
+}
+
+func (a *Application) handleTaskRun(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	task, found := a.currentWorkflow().GetTaskById(id)
+	if !found {
+		writeControlError(w, http.StatusNotFound, messages.TaskNotFound(id))
+		return
+	}
+
+	a.queueTask(task)
+
+	writeJson(w, http.StatusAccepted, controlResponse{Message: messages.TaskQueued(task.GetDisplayName())})
+}
+
+// queueTask runs `task` on the event loop without waiting for it, since tasks can take a long time to run.
+func (a *Application) queueTask(task *Task) {
+	go a.runOnEventLoop(func() { task.RunSync() })
+}
+
+func (a *Application) handleTaskLogs(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	task, found := a.currentWorkflow().GetTaskById(id)
+	if !found || task.logs == nil {
+		writeControlError(w, http.StatusNotFound, messages.TaskNotFound(id))
+		return
+	}
+
+	count, err := strconv.Atoi(r.URL.Query().Get("lines"))
+	if err != nil {
+		count = consts.DEFAULT_LOG_LINES
+	}
+
+	writeJson(w, http.StatusOK, logsResponse{Lines: task.logs.Lines(count)})
+}
+
+func (a *Application) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusAccepted, controlResponse{Message: messages.ShuttingDown()})
+
+	go a.runOnEventLoop(a.exitApp)
+}
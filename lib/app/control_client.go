@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+)
+
+// controlClient sends requests to the control API of a running instance.
+type controlClient struct {
+	client *http.Client
+}
+
+func newControlClient(socketPath string) *controlClient {
+	return &controlClient{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// do sends a request to the control API and decodes the response into `result`.
+func (c *controlClient) do(method string, path string, result any) error {
+	// the host is ignored, since requests are sent to the unix socket
+	req, err := http.NewRequest(method, "http://stackup"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var errResp controlResponse
+		json.NewDecoder(resp.Body).Decode(&errResp)
+
+		return errors.New(errResp.Error)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// runControlCommand runs the `status`, `restart` or `logs` command against the instance that is running
+// the configuration file, and returns the exit code.
+func (a *Application) runControlCommand(command string, args []string) int {
+	client := newControlClient(a.ControlSocketPath())
+
+	var err error
+
+	switch command {
+	case "status":
+		err = a.printStatus(client, a.flags.Json)
+	case "restart":
+		if len(args) == 0 {
+			support.FailureMessageWithXMark(messages.RestartCommandUsage())
+			return 2
+		}
+		err = a.restartServer(client, args[0])
+	case "logs":
+		if len(args) == 0 {
+			support.FailureMessageWithXMark(messages.LogsCommandUsage())
+			return 2
+		}
+		err = a.printLogs(client, args[0], a.flags.Lines)
+	}
+
+	var netErr *net.OpError
+	if errors.As(err, &netErr) {
+		support.FailureMessageWithXMark(messages.NotRunning(a.ConfigFilename))
+		return 1
+	}
+
+	if err != nil {
+		support.FailureMessageWithXMark(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (a *Application) printStatus(client *controlClient, asJson bool) error {
+	var status WorkflowStatus
+	if err := client.do(http.MethodGet, "/status", &status); err != nil {
+		return err
+	}
+
+	return writeStatus(os.Stdout, status, asJson, time.Now())
+}
+
+// writes `status` to `w` as tables of servers and tasks, or as JSON if `asJson` is true.  `now` is used to
+// calculate the uptime of servers.
+func writeStatus(w io.Writer, status WorkflowStatus, asJson bool, now time.Time) error {
+	if asJson {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(status)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "SERVER\tNAME\tSTATE\tPID\tRESTARTS\tUPTIME")
+
+	for _, server := range status.Servers {
+		pid, uptime := "-", "-"
+
+		if server.Pid != 0 {
+			pid = fmt.Sprintf("%d", server.Pid)
+			uptime = now.Sub(server.StartedAt).Round(time.Second).String()
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", server.Id, server.Name, server.State, pid, server.Restarts, uptime)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "TASK\tNAME\tSTATE\tRUNS\tEXIT CODE")
+
+	for _, task := range status.Tasks {
+		exitCode := "-"
+		if task.Runs > 0 {
+			exitCode = fmt.Sprintf("%d", task.ExitCode)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", task.Id, task.Name, task.State, task.Runs, exitCode)
+	}
+
+	return tw.Flush()
+}
+
+func (a *Application) restartServer(client *controlClient, id string) error {
+	var resp controlResponse
+	if err := client.do(http.MethodPost, "/servers/"+url.PathEscape(id)+"/restart", &resp); err != nil {
+		return err
+	}
+
+	support.SuccessMessageWithCheck(resp.Message)
+
+	return nil
+}
+
+func (a *Application) printLogs(client *controlClient, id string, lines int) error {
+	var resp logsResponse
+	if err := client.do(http.MethodGet, fmt.Sprintf("/tasks/%s/logs?lines=%d", url.PathEscape(id), lines), &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Lines) > 0 {
+		fmt.Fprintln(os.Stdout, strings.Join(resp.Lines, "\n"))
+	}
+
+	return nil
+}
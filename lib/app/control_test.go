@@ -0,0 +1,68 @@
+package app_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func controlRequest(t *testing.T, a *app.Application, method string, path string, result any) int {
+	recorder := httptest.NewRecorder()
+	a.ControlHandler().ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+
+	if result != nil {
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(result))
+	}
+
+	return recorder.Code
+}
+
+func TestControlApi(t *testing.T) {
+	requirePosixShell(t)
+
+	task := &app.Task{Id: "web", Command: "sh -c 'echo listening; exec sleep 30'", Path: t.TempDir(), StopTimeout: "1s"}
+
+	a := app.NewApplication()
+	a.Workflow = app.CreateWorkflow(nil, &sync.Map{})
+	a.Workflow.Tasks = []*app.Task{task}
+	task.Initialize(a.Workflow)
+
+	server := task.RunAsync()
+	a.Supervisor.Supervise(server)
+	defer a.Supervisor.StopAll(nil, nil)
+
+	var status app.WorkflowStatus
+	assert.Equal(t, http.StatusOK, controlRequest(t, a, "GET", "/status", &status))
+	assert.Len(t, status.Servers, 1)
+	assert.Equal(t, "web", status.Servers[0].Id)
+	assert.Equal(t, app.ServerRunning, status.Servers[0].State)
+	assert.Equal(t, server.Cmd.Process.Pid, status.Servers[0].Pid)
+
+	var logs struct{ Lines []string }
+	assert.Eventually(t, func() bool {
+		controlRequest(t, a, "GET", "/tasks/web/logs?lines=10", &logs)
+		return len(logs.Lines) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"listening"}, logs.Lines)
+
+	pid := server.Cmd.Process.Pid
+	assert.Equal(t, http.StatusOK, controlRequest(t, a, "POST", "/servers/web/restart", nil))
+	assert.Eventually(t, func() bool {
+		controlRequest(t, a, "GET", "/status", &status)
+		return status.Servers[0].State == app.ServerRunning && status.Servers[0].Pid != pid
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, status.Servers[0].Restarts)
+
+	var resp struct{ Error string }
+	assert.Equal(t, http.StatusNotFound, controlRequest(t, a, "POST", "/servers/missing/stop", &resp))
+	assert.Contains(t, resp.Error, "missing")
+
+	assert.Equal(t, http.StatusOK, controlRequest(t, a, "POST", "/servers/web/stop", nil))
+	assert.Equal(t, app.ServerStopped, server.GetState())
+}
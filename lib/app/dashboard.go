@@ -0,0 +1,342 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eiannone/keyboard"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+)
+
+const (
+	// the number of completed task runs displayed by the dashboard
+	dashboardHistorySize = 8
+	// how often the dashboard is redrawn when nothing has changed, which updates uptimes and schedules
+	dashboardRefreshInterval = time.Second
+	// how long the result of a key press is displayed instead of the most recent message
+	dashboardNoticeDuration = 5 * time.Second
+	// the size used when the terminal's size cannot be determined
+	dashboardDefaultWidth  = 80
+	dashboardDefaultHeight = 24
+)
+
+// dashboard is a full-screen view of the running workflow, which can restart, start and stop servers and
+// run tasks using the keyboard.  Messages that would be displayed while it is open are kept so that the
+// most recent one can be displayed instead.
+type dashboard struct {
+	app         *Application
+	out         io.Writer
+	messages    *logBuffer
+	history     []TaskHistoryEntry
+	focus       DashboardFocus
+	selected    int
+	notice      string
+	noticeAt    time.Time
+	redraw      chan struct{}
+	closed      chan struct{}
+	closeOnce   sync.Once
+	unsubscribe func()
+	lock        sync.Mutex
+}
+
+// newDashboard creates a dashboard that records task history from now on, but is not displayed until it
+// is opened.
+func newDashboard(a *Application, out io.Writer) *dashboard {
+	d := &dashboard{
+		app:      a,
+		out:      out,
+		messages: newLogBuffer(dashboardHistorySize),
+		history:  []TaskHistoryEntry{},
+		redraw:   make(chan struct{}, 1),
+		closed:   make(chan struct{}),
+	}
+
+	d.unsubscribe = events.Subscribe(d.handleEvent)
+
+	return d
+}
+
+// canDisplayDashboard returns true if stdout is a terminal, which the dashboard is drawn on.
+func canDisplayDashboard() bool {
+	info, err := os.Stdout.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// handleEvent records completed task runs in the dashboard's history, and redraws it when the state of a
+// task or server changes.
+func (d *dashboard) handleEvent(event events.Event) {
+	switch event.Type {
+	case events.TaskFinished, events.TaskSkipped:
+		entry := TaskHistoryEntry{Time: event.Time, Task: event.Task, Status: event.Status}
+
+		if event.Type == events.TaskSkipped {
+			entry.Status = "skipped"
+		}
+		if event.DurationMs != nil {
+			entry.Duration = time.Duration(*event.DurationMs) * time.Millisecond
+		}
+
+		d.lock.Lock()
+		d.history = append([]TaskHistoryEntry{entry}, d.history...)
+		if len(d.history) > dashboardHistorySize {
+			d.history = d.history[:dashboardHistorySize]
+		}
+		d.lock.Unlock()
+	case events.TaskOutput:
+		return
+	}
+
+	d.requestRedraw()
+}
+
+func (d *dashboard) requestRedraw() {
+	select {
+	case d.redraw <- struct{}{}:
+	default:
+	}
+}
+
+// open switches the terminal to the dashboard and handles key presses until the dashboard is closed.
+func (d *dashboard) open() error {
+	keys, err := keyboard.GetKeys(10)
+	if err != nil {
+		return err
+	}
+
+	support.SetMessageOutput(d.messages)
+
+	// use the terminal's alternate screen, so that its contents are restored when the dashboard is closed
+	fmt.Fprint(d.out, "\x1b[?1049h\x1b[?25l")
+
+	go d.run(keys)
+
+	return nil
+}
+
+func (d *dashboard) run(keys <-chan keyboard.KeyEvent) {
+	ticker := time.NewTicker(dashboardRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		d.draw()
+
+		select {
+		case <-d.closed:
+			return
+		case <-ticker.C:
+		case <-d.redraw:
+		case event, ok := <-keys:
+			if !ok {
+				return
+			}
+
+			if event.Key == keyboard.KeyCtrlC || event.Rune == 'q' {
+				d.app.exitApp()
+				return
+			}
+
+			d.handleKey(event.Rune, event.Key)
+		}
+	}
+}
+
+// close restores the terminal, after which messages are displayed as usual.
+func (d *dashboard) close() {
+	d.closeOnce.Do(func() {
+		close(d.closed)
+		d.unsubscribe()
+		keyboard.Close()
+
+		fmt.Fprint(d.out, "\x1b[?25h\x1b[?1049l")
+		support.SetMessageOutput(os.Stdout)
+	})
+}
+
+// tasksOf returns the tasks in `status` that are not servers, since servers are controlled separately.
+func tasksOf(status WorkflowStatus) []TaskStatus {
+	servers := map[string]bool{}
+	for _, server := range status.Servers {
+		servers[server.Id] = true
+	}
+
+	result := []TaskStatus{}
+	for _, task := range status.Tasks {
+		if !servers[task.Id] {
+			result = append(result, task)
+		}
+	}
+
+	return result
+}
+
+func (d *dashboard) handleKey(char rune, key keyboard.Key) {
+	status := d.app.GetStatus()
+	tasks := tasksOf(status)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	count := len(status.Servers)
+	if d.focus == FocusTasks {
+		count = len(tasks)
+	}
+
+	switch {
+	case key == keyboard.KeyTab:
+		d.focus = 1 - d.focus
+		d.selected = 0
+	case key == keyboard.KeyArrowUp || char == 'k':
+		d.selected = max(d.selected-1, 0)
+	case key == keyboard.KeyArrowDown || char == 'j':
+		d.selected = min(d.selected+1, max(count-1, 0))
+	case d.selected >= count:
+		return
+	case d.focus == FocusTasks && (key == keyboard.KeyEnter || char == 'r'):
+		d.runTask(tasks[d.selected].Id)
+	case d.focus == FocusServers && char == 'r':
+		d.perform(status.Servers[d.selected].Id, d.app.restartServerProcess)
+	case d.focus == FocusServers && char == 's':
+		d.perform(status.Servers[d.selected].Id, d.app.startStoppedServer)
+	case d.focus == FocusServers && char == 'x':
+		d.perform(status.Servers[d.selected].Id, d.app.stopServerProcess)
+	}
+}
+
+func (d *dashboard) runTask(id string) {
+	task, found := d.app.currentWorkflow().GetTaskById(id)
+	if !found {
+		return
+	}
+
+	d.app.queueTask(task)
+}
+
+// perform runs `action` on the server with the id `id` in the background, since stopping a server can take
+// as long as its stop timeout, and displays the result.
+func (d *dashboard) perform(id string, action func(*ServerProcess) (string, error)) {
+	server, found := d.app.Supervisor.FindServer(id)
+	if !found {
+		return
+	}
+
+	go func() {
+		message, err := action(server)
+		if err != nil {
+			message = err.Error()
+		}
+
+		d.lock.Lock()
+		d.notice, d.noticeAt = message, time.Now()
+		d.lock.Unlock()
+
+		d.requestRedraw()
+	}()
+}
+
+// snapshot returns the current state of the workflow, as displayed by the dashboard.
+func (d *dashboard) snapshot(now time.Time) DashboardView {
+	status := d.app.GetStatus()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	view := DashboardView{
+		Name:      status.Name,
+		Now:       now,
+		Servers:   status.Servers,
+		Logs:      map[string][]string{},
+		Tasks:     tasksOf(status),
+		Scheduled: d.app.getScheduledRuns(),
+		History:   append([]TaskHistoryEntry{}, d.history...),
+		Focus:     d.focus,
+		Selected:  d.selected,
+	}
+
+	if now.Sub(d.noticeAt) < dashboardNoticeDuration {
+		view.Notice = d.notice
+	} else if lines := d.messages.Lines(1); len(lines) > 0 {
+		view.Notice = strings.TrimSpace(lines[0])
+	}
+
+	for _, server := range status.Servers {
+		if task, found := d.app.currentWorkflow().GetTaskById(server.Id); found && task.logs != nil {
+			view.Logs[server.Id] = task.logs.Lines(-1)
+		}
+	}
+
+	return view
+}
+
+func (d *dashboard) draw() {
+	width, height, found := terminalSize()
+	if !found {
+		width, height = dashboardDefaultWidth, dashboardDefaultHeight
+	}
+
+	lines := d.snapshot(time.Now()).Render(width, height)
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H")
+
+	for i, line := range lines {
+		sb.WriteString(line + "\x1b[K")
+		if i < len(lines)-1 {
+			sb.WriteString("\r\n")
+		}
+	}
+
+	sb.WriteString("\x1b[J")
+
+	fmt.Fprint(d.out, sb.String())
+}
+
+// getScheduledRuns returns the next run of each scheduled task, soonest first.
+func (a *Application) getScheduledRuns() []ScheduledRun {
+	result := []ScheduledRun{}
+
+	a.scheduleLock.Lock()
+	defer a.scheduleLock.Unlock()
+
+	for _, entry := range a.cronEngine.Entries() {
+		def, found := a.scheduledTasks[entry.ID]
+		if !found {
+			continue
+		}
+
+		result = append(result, ScheduledRun{Task: def.TaskId(), Cron: def.Cron, Next: entry.Next})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Next.Before(result[j].Next)
+	})
+
+	return result
+}
+
+// startStoppedServer starts a server that is no longer running.
+func (a *Application) startStoppedServer(server *ServerProcess) (string, error) {
+	if server.IsActive() {
+		return messages.ServerAlreadyRunning(server.Task.GetDisplayName()), nil
+	}
+
+	if err := a.startServerProcess(server); err != nil {
+		return "", err
+	}
+
+	return messages.ServerStartRequested(server.Task.GetDisplayName()), nil
+}
+
+// stopServerProcess stops a server without restarting it.
+func (a *Application) stopServerProcess(server *ServerProcess) (string, error) {
+	a.Supervisor.StopServer(server, a.SignalCommandCallback, a.KillCommandCallback)
+
+	return messages.ServerStopped(server.Task.GetDisplayName(), string(server.GetState())), nil
+}
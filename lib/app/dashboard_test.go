@@ -0,0 +1,63 @@
+package app_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardRender(t *testing.T) {
+	now := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+
+	view := app.DashboardView{
+		Name: "my project",
+		Now:  now,
+		Servers: []app.ServerStatus{
+			{Id: "web", State: app.ServerRunning, Pid: 1234, Restarts: 1, StartedAt: now.Add(-90 * time.Second)},
+			{Id: "queue", State: app.ServerFailed},
+		},
+		Logs: map[string][]string{
+			"web":   {"line 1", "line 2", "line 3", "line 4", "\x1b[32mlistening\x1b[0m"},
+			"queue": {"connection refused"},
+		},
+		Tasks:     []app.TaskStatus{{Id: "build", State: "succeeded", Runs: 2}, {Id: "web", State: "started", Runs: 1}},
+		Scheduled: []app.ScheduledRun{{Task: "backup", Cron: "*/5 * * * *", Next: now.Add(5 * time.Minute)}},
+		History:   []app.TaskHistoryEntry{{Time: now.Add(-time.Minute), Task: "build", Status: "failed", Duration: 1500 * time.Millisecond}},
+		Focus:     app.FocusServers,
+		Selected:  1,
+		Notice:    "Restarting server web...",
+	}
+
+	lines := view.Render(100, 30)
+	text := strings.Join(lines, "\n")
+
+	assert.Len(t, lines, 30)
+	for _, line := range lines {
+		assert.LessOrEqual(t, utf8.RuneCountInString(line), 100)
+	}
+
+	assert.True(t, strings.HasPrefix(lines[0], "StackUp · my project"))
+	assert.True(t, strings.HasSuffix(lines[0], "10:00:00"))
+	assert.Regexp(t, `web\s+running\s+1234\s+1\s+1m30s`, text)
+	assert.Regexp(t, `›\s+queue\s+failed\s+-\s+0\s+-`, text)
+	assert.Regexp(t, `backup\s+10:05\s+in 5m0s`, text)
+	assert.Regexp(t, `09:59:00\s+✗ build\s+1.5s`, text)
+	assert.Contains(t, text, "── web (running) ")
+	assert.Contains(t, text, "\nlistening\n")
+	assert.Contains(t, text, "connection refused")
+
+	// the footer is displayed at the bottom of the terminal
+	assert.Equal(t, "Restarting server web...", lines[28])
+	assert.Contains(t, lines[29], "r restart")
+
+	view.Focus, view.Selected = app.FocusTasks, 0
+	lines = view.Render(60, 16)
+
+	assert.Len(t, lines, 16)
+	assert.Contains(t, lines[15], "enter run task")
+	assert.Regexp(t, `›\s+build\s+succeeded\s+2`, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,284 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+)
+
+type DashboardFocus int
+
+const (
+	FocusServers DashboardFocus = iota
+	FocusTasks
+)
+
+// the minimum number of log lines displayed for each server
+const dashboardMinLogLines = 3
+
+// matches the escape sequences used to colour terminal output
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// ScheduledRun is the next time that a scheduled task will run.
+type ScheduledRun struct {
+	Task string
+	Cron string
+	Next time.Time
+}
+
+// TaskHistoryEntry is a completed or skipped run of a task.
+type TaskHistoryEntry struct {
+	Time     time.Time
+	Task     string
+	Status   string
+	Duration time.Duration
+}
+
+// DashboardView is a snapshot of everything that the dashboard displays.  `Selected` is the index of the
+// selected server or task, depending on `Focus`.
+type DashboardView struct {
+	Name      string
+	Now       time.Time
+	Servers   []ServerStatus
+	Logs      map[string][]string
+	Tasks     []TaskStatus
+	Scheduled []ScheduledRun
+	History   []TaskHistoryEntry
+	Focus     DashboardFocus
+	Selected  int
+	Notice    string
+}
+
+// Render returns the lines of the dashboard for a terminal of `width` columns and `height` rows.
+func (v DashboardView) Render(width int, height int) []string {
+	result := []string{}
+
+	title := "StackUp"
+	if v.Name != "" {
+		title += " · " + v.Name
+	}
+	result = append(result, justify(title, v.Now.Format("15:04:05"), width), "")
+
+	result = append(result, v.serverLines()...)
+	result = append(result, "")
+	result = append(result, sideBySide(v.taskLines(), append(v.scheduleLines(), v.historyLines()...), width)...)
+
+	footer := []string{"", sanitizeLine(v.Notice), v.keyHelp()}
+	height = max(height, len(footer)+1)
+
+	logHeight := height - len(result) - len(footer)
+	result = append(result, v.logLines(logHeight, width)...)
+
+	// the log panes fill the remaining rows, so the footer is always displayed at the bottom
+	for len(result) < height-len(footer) {
+		result = append(result, "")
+	}
+
+	result = append(result, footer...)
+
+	if len(result) > height {
+		result = append(result[:height-len(footer)], footer...)
+	}
+
+	for i, line := range result {
+		result[i] = truncate(line, width)
+	}
+
+	return result
+}
+
+func (v DashboardView) cursor(focus DashboardFocus, index int) string {
+	if v.Focus == focus && v.Selected == index {
+		return "›"
+	}
+
+	return " "
+}
+
+func (v DashboardView) serverLines() []string {
+	if len(v.Servers) == 0 {
+		return []string{"SERVERS", "  none"}
+	}
+
+	rows := []string{" \tSERVER\tSTATE\tPID\tRESTARTS\tUPTIME"}
+
+	for i, server := range v.Servers {
+		pid, uptime := "-", "-"
+
+		if server.Pid != 0 {
+			pid = fmt.Sprintf("%d", server.Pid)
+			uptime = v.Now.Sub(server.StartedAt).Round(time.Second).String()
+		}
+
+		rows = append(rows, fmt.Sprintf("%s\t%s\t%s\t%s\t%d\t%s", v.cursor(FocusServers, i), server.Id, server.State, pid, server.Restarts, uptime))
+	}
+
+	return formatTable(rows)
+}
+
+func (v DashboardView) taskLines() []string {
+	rows := []string{" \tTASK\tSTATE\tRUNS"}
+
+	for i, task := range v.Tasks {
+		rows = append(rows, fmt.Sprintf("%s\t%s\t%s\t%d", v.cursor(FocusTasks, i), task.Id, task.State, task.Runs))
+	}
+
+	return formatTable(rows)
+}
+
+func (v DashboardView) scheduleLines() []string {
+	result := []string{"SCHEDULED"}
+
+	if len(v.Scheduled) == 0 {
+		return append(result, "  none", "")
+	}
+
+	rows := []string{}
+	for _, run := range v.Scheduled {
+		rows = append(rows, fmt.Sprintf("  %s\t%s\tin %s", run.Task, run.Next.Format("15:04"), run.Next.Sub(v.Now).Round(time.Second)))
+	}
+
+	return append(append(result, formatTable(rows)...), "")
+}
+
+func (v DashboardView) historyLines() []string {
+	result := []string{"RECENT TASKS"}
+
+	if len(v.History) == 0 {
+		return append(result, "  none")
+	}
+
+	rows := []string{}
+	for _, entry := range v.History {
+		symbol := "✓"
+		switch entry.Status {
+		case "skipped":
+			symbol = "↷"
+		case "failed", "timeout":
+			symbol = "✗"
+		}
+
+		rows = append(rows, fmt.Sprintf("  %s\t%s %s\t%s", entry.Time.Format("15:04:05"), symbol, entry.Task, entry.Duration.Round(time.Millisecond)))
+	}
+
+	return append(result, formatTable(rows)...)
+}
+
+// logLines returns a pane of the most recent output of each server, dividing `height` rows between them.
+func (v DashboardView) logLines(height int, width int) []string {
+	result := []string{}
+
+	if len(v.Servers) == 0 || height < 2 {
+		return result
+	}
+
+	lineCount := max(height/len(v.Servers)-1, dashboardMinLogLines)
+
+	for _, server := range v.Servers {
+		if len(result)+1 >= height {
+			break
+		}
+
+		heading := fmt.Sprintf("── %s (%s) ", server.Id, server.State)
+		result = append(result, heading+strings.Repeat("─", max(width-utf8.RuneCountInString(heading), 0)))
+
+		lines := v.Logs[server.Id]
+		if len(lines) > lineCount {
+			lines = lines[len(lines)-lineCount:]
+		}
+
+		for _, line := range lines {
+			result = append(result, sanitizeLine(line))
+		}
+
+		for i := len(lines); i < lineCount; i++ {
+			result = append(result, "")
+		}
+	}
+
+	if len(result) > height {
+		result = result[:height]
+	}
+
+	return result
+}
+
+func (v DashboardView) keyHelp() string {
+	if v.Focus == FocusTasks {
+		return "↑/↓ select   enter run task   tab servers   q quit"
+	}
+
+	return "↑/↓ select   r restart   s start   x stop   tab tasks   q quit"
+}
+
+// formatTable aligns the tab-separated columns of `rows`.
+func formatTable(rows []string) []string {
+	var buf bytes.Buffer
+
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(tw, row)
+	}
+	tw.Flush()
+
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// sideBySide returns the lines of `left` and `right` as two columns, each half of `width` wide.
+func sideBySide(left []string, right []string, width int) []string {
+	columnWidth := width / 2
+	result := []string{}
+
+	for i := 0; i < max(len(left), len(right)); i++ {
+		var l, r string
+
+		if i < len(left) {
+			l = truncate(left[i], columnWidth-1)
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+
+		result = append(result, strings.TrimRight(l+strings.Repeat(" ", columnWidth-utf8.RuneCountInString(l))+r, " "))
+	}
+
+	return result
+}
+
+// justify returns `left` and `right` separated by enough spaces for the line to be `width` wide.
+func justify(left string, right string, width int) string {
+	spaces := max(width-utf8.RuneCountInString(left)-utf8.RuneCountInString(right), 1)
+
+	return left + strings.Repeat(" ", spaces) + right
+}
+
+// truncate shortens `line` to at most `width` characters.
+func truncate(line string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(line) <= width {
+		return line
+	}
+
+	return string([]rune(line)[:width])
+}
+
+// sanitizeLine removes colours and control characters from a line of command output so that it cannot
+// change the layout of the dashboard.
+func sanitizeLine(line string) string {
+	line = ansiEscapePattern.ReplaceAllString(line, "")
+	line = strings.ReplaceAll(line, "\t", "    ")
+
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, line)
+}
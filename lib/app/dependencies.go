@@ -0,0 +1,146 @@
+package app
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+)
+
+type dependencyVisitState int
+
+const (
+	dependencyUnvisited dependencyVisitState = iota
+	dependencyVisiting
+	dependencyVisited
+)
+
+// DependencyCycleError is returned when the `depends-on` definitions of one or more tasks form a cycle.
+// `TaskIds` contains the ids of the tasks in the cycle, starting and ending with the same id.
+type DependencyCycleError struct {
+	TaskIds []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return messages.TaskDependencyCycle(e.TaskIds)
+}
+
+// ResolveDependencies returns all direct and indirect dependencies of `task`, ordered so that each task
+// appears after the tasks it depends on.  `task` itself is not included in the result.
+func (workflow *StackupWorkflow) ResolveDependencies(task *Task) ([]*Task, error) {
+	result := []*Task{}
+	states := map[*Task]dependencyVisitState{}
+
+	err := workflow.visitDependencies(task, states, []string{}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result[:len(result)-1], nil
+}
+
+// visitDependencies performs a depth-first traversal of the dependency graph starting at `task`,
+// appending each task to `result` after all of its dependencies have been appended.
+func (workflow *StackupWorkflow) visitDependencies(task *Task, states map[*Task]dependencyVisitState, path []string, result *[]*Task) error {
+	path = append(path, task.Id)
+
+	switch states[task] {
+	case dependencyVisited:
+		return nil
+	case dependencyVisiting:
+		return &DependencyCycleError{TaskIds: cyclePath(path)}
+	}
+
+	states[task] = dependencyVisiting
+
+	for _, id := range task.DependsOn {
+		dependency, found := workflow.GetTaskById(id)
+		if !found {
+			return errors.New(messages.TaskDependencyNotFound(task.Id, id))
+		}
+
+		if err := workflow.visitDependencies(dependency, states, path, result); err != nil {
+			return err
+		}
+	}
+
+	states[task] = dependencyVisited
+	*result = append(*result, task)
+
+	return nil
+}
+
+// cyclePath trims `path` so that it begins at the first occurrence of the task that closes the cycle.
+func cyclePath(path []string) []string {
+	last := path[len(path)-1]
+
+	for i, id := range path {
+		if id == last {
+			return path[i:]
+		}
+	}
+
+	return path
+}
+
+// CheckDependencies verifies that every `depends-on` entry references an existing task and that there
+// are no dependency cycles.  Each problem found is returned as a separate error.
+func (workflow *StackupWorkflow) CheckDependencies() []error {
+	result := []error{}
+	seen := map[string]bool{}
+
+	for _, task := range workflow.Tasks {
+		if len(task.DependsOn) == 0 {
+			continue
+		}
+
+		_, err := workflow.ResolveDependencies(task)
+		if err == nil || seen[dependencyErrorKey(err)] {
+			continue
+		}
+
+		seen[dependencyErrorKey(err)] = true
+		result = append(result, err)
+	}
+
+	return result
+}
+
+// dependencyErrorKey returns a key used to report each cycle only once, regardless of which task in
+// the cycle it was found from.
+func dependencyErrorKey(err error) string {
+	var cycleErr *DependencyCycleError
+	if !errors.As(err, &cycleErr) {
+		return err.Error()
+	}
+
+	ids := append([]string{}, cycleErr.TaskIds[1:]...)
+	sort.Strings(ids)
+
+	return strings.Join(ids, ",")
+}
+
+// runDependencies runs each dependency of the task that has not already completed successfully, in
+// dependency order.  Returns false if a dependency could not be resolved or failed to run.
+func (task *Task) runDependencies() bool {
+	if len(task.DependsOn) == 0 || task.Workflow == nil {
+		return true
+	}
+
+	dependencies, err := task.Workflow.ResolveDependencies(task)
+	if err != nil {
+		support.FailureMessageWithXMark(err.Error())
+		return false
+	}
+
+	for _, dependency := range dependencies {
+		if !task.Workflow.State.runOnce(dependency) {
+			support.FailureMessageWithXMark(messages.TaskDependencyFailed(task.GetDisplayName(), dependency.GetDisplayName()))
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,154 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func createDependencyWorkflow(tasks ...*app.Task) *app.StackupWorkflow {
+	workflow := app.CreateWorkflow(nil, nil)
+	workflow.Tasks = tasks
+
+	return workflow
+}
+
+func taskIds(tasks []*app.Task) []string {
+	result := []string{}
+	for _, task := range tasks {
+		result = append(result, task.Id)
+	}
+
+	return result
+}
+
+func TestResolveDependencies(t *testing.T) {
+	workflow := createDependencyWorkflow(
+		&app.Task{Id: "serve", DependsOn: []string{"migrate", "npm-ci"}},
+		&app.Task{Id: "migrate", DependsOn: []string{"composer-install", "start-containers"}},
+		&app.Task{Id: "composer-install"},
+		&app.Task{Id: "npm-ci"},
+		&app.Task{Id: "start-containers"},
+	)
+
+	task, _ := workflow.GetTaskById("serve")
+	deps, err := workflow.ResolveDependencies(task)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"composer-install", "start-containers", "migrate", "npm-ci"}, taskIds(deps))
+
+	task, _ = workflow.GetTaskById("npm-ci")
+	deps, err = workflow.ResolveDependencies(task)
+
+	assert.NoError(t, err)
+	assert.Empty(t, deps)
+	assert.Empty(t, workflow.CheckDependencies())
+}
+
+func TestResolveDependenciesSharedDependency(t *testing.T) {
+	workflow := createDependencyWorkflow(
+		&app.Task{Id: "a", DependsOn: []string{"b", "c"}},
+		&app.Task{Id: "b", DependsOn: []string{"d"}},
+		&app.Task{Id: "c", DependsOn: []string{"d"}},
+		&app.Task{Id: "d"},
+	)
+
+	task, _ := workflow.GetTaskById("a")
+	deps, err := workflow.ResolveDependencies(task)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d", "b", "c"}, taskIds(deps))
+}
+
+func TestResolveDependenciesDetectsCycles(t *testing.T) {
+	workflow := createDependencyWorkflow(
+		&app.Task{Id: "a", DependsOn: []string{"b"}},
+		&app.Task{Id: "b", DependsOn: []string{"c"}},
+		&app.Task{Id: "c", DependsOn: []string{"a"}},
+		&app.Task{Id: "d", DependsOn: []string{"d"}},
+	)
+
+	task, _ := workflow.GetTaskById("a")
+	_, err := workflow.ResolveDependencies(task)
+
+	var cycleErr *app.DependencyCycleError
+	assert.ErrorAs(t, err, &cycleErr)
+	assert.Equal(t, []string{"a", "b", "c", "a"}, cycleErr.TaskIds)
+
+	errs := workflow.CheckDependencies()
+	assert.Len(t, errs, 2)
+	assert.Contains(t, errs[1].Error(), "d -> d")
+}
+
+func TestCheckDependenciesReportsMissingTasks(t *testing.T) {
+	workflow := createDependencyWorkflow(
+		&app.Task{Id: "a", DependsOn: []string{"missing"}},
+	)
+
+	errs := workflow.CheckDependencies()
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "missing")
+}
+
+func TestSharedDependencyRunsOnceForDependentsRunningTogether(t *testing.T) {
+	requirePosixShell(t)
+
+	dir := t.TempDir()
+	workflow := app.CreateWorkflow(nil, &sync.Map{})
+	workflow.Tasks = []*app.Task{
+		{Id: "a", Command: "true", DependsOn: []string{"shared"}, Path: dir},
+		{Id: "b", Command: "true", DependsOn: []string{"shared"}, Path: dir},
+		{Id: "shared", Command: "sh -c 'echo shared >> deps.log; sleep 0.2'", Path: dir},
+	}
+
+	for _, task := range workflow.Tasks {
+		task.Initialize(workflow)
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b"} {
+		task, _ := workflow.GetTaskById(id)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.True(t, task.RunSync())
+		}()
+	}
+	wg.Wait()
+
+	contents, _ := os.ReadFile(filepath.Join(dir, "deps.log"))
+	assert.Equal(t, []string{"shared"}, strings.Fields(string(contents)))
+}
+
+func TestSkippedDependenciesDoNotFailDependents(t *testing.T) {
+	requirePosixShell(t)
+
+	otherPlatform := "windows"
+	dir := t.TempDir()
+	workflow := app.CreateWorkflow(nil, &sync.Map{})
+	workflow.Tasks = []*app.Task{
+		{Id: "build", Command: "sh -c 'echo build >> deps.log'", DependsOn: []string{"other-platform", "failing"}, Path: dir},
+		{Id: "deploy", Command: "sh -c 'echo deploy >> deps.log'", DependsOn: []string{"other-platform"}, Path: dir},
+		{Id: "other-platform", Command: "sh -c 'echo other >> deps.log'", Platforms: []string{otherPlatform}, Path: dir},
+		{Id: "failing", Command: "sh -c 'exit 1'", Path: dir},
+	}
+
+	for _, task := range workflow.Tasks {
+		task.Initialize(workflow)
+	}
+
+	deploy, _ := workflow.GetTaskById("deploy")
+	assert.True(t, deploy.RunSync())
+
+	build, _ := workflow.GetTaskById("build")
+	assert.False(t, build.RunSync())
+
+	contents, _ := os.ReadFile(filepath.Join(dir, "deps.log"))
+	assert.Equal(t, []string{"deploy"}, strings.Fields(string(contents)))
+}
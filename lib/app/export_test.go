@@ -0,0 +1,6 @@
+package app
+
+// RunEventLoop runs the event loop, so that tests can perform actions that must run on the main goroutine.
+func (a *Application) RunEventLoop() {
+	a.runEventLoop()
+}
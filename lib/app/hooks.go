@@ -0,0 +1,203 @@
+package app
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+)
+
+// TaskHooks run a task or evaluate a script around each run of a task.  The `before` hook runs before the
+// task's command, and the task does not run if it fails.  The other hooks run after the command, with the
+// `on-success` or `on-failure` hook running before the `after` hook.
+type TaskHooks struct {
+	Before    string `yaml:"before,omitempty"`
+	After     string `yaml:"after,omitempty"`
+	OnSuccess string `yaml:"on-success,omitempty"`
+	OnFailure string `yaml:"on-failure,omitempty"`
+}
+
+// WorkflowHooks run a task or evaluate a script when the workflow starts, once all servers are ready, when
+// the application exits, and when something fails while the workflow is running.
+type WorkflowHooks struct {
+	OnStart    string `yaml:"on-start,omitempty"`
+	OnReady    string `yaml:"on-ready,omitempty"`
+	OnShutdown string `yaml:"on-shutdown,omitempty"`
+	OnError    string `yaml:"on-error,omitempty"`
+}
+
+// values returns the hooks in the order that they run.
+func (th *TaskHooks) values() []string {
+	return []string{th.Before, th.OnSuccess, th.OnFailure, th.After}
+}
+
+func (wh *WorkflowHooks) values() []string {
+	return []string{wh.OnStart, wh.OnReady, wh.OnShutdown, wh.OnError}
+}
+
+// isHookTask returns true if `task` is run by one of the workflow's hooks or one of its tasks' hooks.
+func (workflow *StackupWorkflow) isHookTask(task *Task) bool {
+	hooks := workflow.Hooks.values()
+
+	for _, t := range workflow.Tasks {
+		if t.Hooks != nil {
+			hooks = append(hooks, t.Hooks.values()...)
+		}
+	}
+
+	for _, hook := range hooks {
+		if task.Id != "" && strings.EqualFold(hook, task.Id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runHook runs the task with the id `hook`, or evaluates it if it is a script.  It returns false if the
+// task failed or the script returned false.  Tasks whose hooks are already running are not run again, so
+// that hooks that refer to each other cannot run forever.
+func (workflow *StackupWorkflow) runHook(name string, hook string) bool {
+	if hook == "" {
+		return true
+	}
+
+	if workflow.JsEngine.IsEvaluatableScriptString(hook) {
+		result, isBool := workflow.JsEngine.Evaluate(hook).(bool)
+		return !isBool || result
+	}
+
+	task, found := workflow.GetTaskById(hook)
+	if !found {
+		support.SkippedMessageWithSymbol(messages.TaskNotFound(hook))
+		return true
+	}
+
+	if task.runningHooks.Load() {
+		support.WarningMessage(messages.HookSkippedRecursion(name, task.GetDisplayName()))
+		return true
+	}
+
+	return task.RunSync()
+}
+
+// runBeforeHook runs the task's `before` hook, and returns false if it failed.
+func (task *Task) runBeforeHook() bool {
+	if task.Hooks == nil || task.Hooks.Before == "" {
+		return true
+	}
+
+	task.runningHooks.Store(true)
+	defer task.runningHooks.Store(false)
+
+	return task.Workflow.runHook("before", task.Hooks.Before)
+}
+
+// runCompletionHooks runs the task's `on-success` or `on-failure` hook, depending on the result of its
+// command, followed by its `after` hook.
+func (task *Task) runCompletionHooks(success bool) {
+	if task.Hooks == nil {
+		return
+	}
+
+	task.runningHooks.Store(true)
+	defer task.runningHooks.Store(false)
+
+	if success {
+		task.Workflow.runHook("on-success", task.Hooks.OnSuccess)
+	} else {
+		task.Workflow.runHook("on-failure", task.Hooks.OnFailure)
+	}
+
+	task.Workflow.runHook("after", task.Hooks.After)
+}
+
+// runWorkflowHook runs the workflow's hook named `name`, reporting if it failed.
+func (a *Application) runWorkflowHook(name string, hook string) {
+	if hook == "" {
+		return
+	}
+
+	if !a.Workflow.runHook(name, hook) {
+		support.FailureMessageWithXMark(messages.HookFailed(name))
+	}
+}
+
+// isWorkflowError returns true if `event` is a failure that the workflow's `on-error` hook runs for.
+func isWorkflowError(event events.Event) bool {
+	switch event.Type {
+	case events.TaskFinished:
+		return event.Status != "success"
+	case events.ServerExited, events.ServerNotReady, events.IncludeFailed, events.WorkflowReloadFailed:
+		return true
+	}
+
+	return false
+}
+
+// errorHook runs the workflow's `on-error` hook when a task fails, a server exits unexpectedly or is not
+// ready, or the workflow cannot be reloaded.  Failures caused by the hook itself are ignored.  Scripts can only be
+// evaluated on the main goroutine, so failures that happen before the event loop starts are kept until
+// the main goroutine runs the hook with runPending.
+type errorHook struct {
+	app         *Application
+	running     atomic.Bool
+	pending     bool
+	loopStarted bool
+	lock        sync.Mutex
+}
+
+// watchForErrors runs the `on-error` hook for each failure from now on.
+func (a *Application) watchForErrors() {
+	a.errorHook = &errorHook{app: a}
+
+	events.Subscribe(a.errorHook.handleEvent)
+}
+
+func (eh *errorHook) handleEvent(event events.Event) {
+	if !isWorkflowError(event) || eh.running.Load() || eh.app.currentWorkflow().Hooks.OnError == "" {
+		return
+	}
+
+	eh.lock.Lock()
+	if !eh.loopStarted {
+		eh.pending = true
+		eh.lock.Unlock()
+		return
+	}
+	eh.lock.Unlock()
+
+	// events can be emitted by the event loop itself, so the hook is queued without waiting for it
+	go eh.app.runOnEventLoop(eh.run)
+}
+
+// run runs the hook, unless it is already running.  It must be called from the main goroutine.
+func (eh *errorHook) run() {
+	if !eh.running.CompareAndSwap(false, true) {
+		return
+	}
+	defer eh.running.Store(false)
+
+	eh.app.runWorkflowHook("on-error", eh.app.Workflow.Hooks.OnError)
+}
+
+// runPending runs the hook if something failed since it was last called, and queues the hook on the event
+// loop for failures from now on if `loopStarted` is true.  It must be called from the main goroutine.
+func (eh *errorHook) runPending(loopStarted bool) {
+	if eh == nil {
+		return
+	}
+
+	eh.lock.Lock()
+	pending := eh.pending
+	eh.pending = false
+	eh.loopStarted = eh.loopStarted || loopStarted
+	eh.lock.Unlock()
+
+	if pending {
+		eh.run()
+	}
+}
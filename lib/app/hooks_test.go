@@ -0,0 +1,120 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskHooksRunAroundTasks(t *testing.T) {
+	requirePosixShell(t)
+
+	dir := t.TempDir()
+	logged := func(name string, exitCode string) *app.Task {
+		return &app.Task{Id: name, Command: "sh -c 'echo " + name + " >> hooks.log; exit " + exitCode + "'", Path: dir}
+	}
+
+	build := logged("build", "0")
+	build.Hooks = &app.TaskHooks{Before: "prepare", OnSuccess: "notify", OnFailure: "cleanup", After: "done"}
+
+	deploy := logged("deploy", "1")
+	deploy.Hooks = &app.TaskHooks{OnSuccess: "notify", OnFailure: "cleanup", After: "done"}
+
+	guarded := logged("guarded", "0")
+	guarded.Hooks = &app.TaskHooks{Before: "check"}
+
+	// hooks that run the task itself are skipped
+	loop := logged("loop", "0")
+	loop.Hooks = &app.TaskHooks{After: "loop"}
+
+	workflow := app.CreateWorkflow(nil, &sync.Map{})
+	workflow.Tasks = []*app.Task{
+		build, deploy, guarded, loop,
+		logged("prepare", "0"), logged("notify", "0"), logged("cleanup", "0"), logged("done", "0"), logged("check", "1"),
+	}
+
+	for _, task := range workflow.Tasks {
+		task.Initialize(workflow)
+	}
+
+	run := func(task *app.Task) (bool, string) {
+		os.Remove(filepath.Join(dir, "hooks.log"))
+		result := task.RunSync()
+		contents, _ := os.ReadFile(filepath.Join(dir, "hooks.log"))
+
+		return result, strings.Join(strings.Fields(string(contents)), " ")
+	}
+
+	result, log := run(build)
+	assert.True(t, result)
+	assert.Equal(t, "prepare build notify done", log)
+
+	result, log = run(deploy)
+	assert.False(t, result)
+	assert.Equal(t, "deploy cleanup done", log)
+
+	result, log = run(guarded)
+	assert.False(t, result)
+	assert.Equal(t, "check", log)
+	assert.Equal(t, 1, guarded.ExitCode())
+
+	result, log = run(loop)
+	assert.True(t, result)
+	assert.Equal(t, "loop", log)
+}
+
+func TestHooksCanRunTasksWithDependencies(t *testing.T) {
+	requirePosixShell(t)
+
+	dir := t.TempDir()
+	logged := func(name string) *app.Task {
+		return &app.Task{Id: name, Command: "sh -c 'echo " + name + " >> hooks.log'", Path: dir}
+	}
+
+	a, b, c, d := logged("a"), logged("b"), logged("c"), logged("d")
+	a.DependsOn = []string{"b"}
+	b.Hooks = &app.TaskHooks{After: "c"}
+	c.DependsOn = []string{"d"}
+
+	// a task that its own hook depends on cannot run
+	e, f := logged("e"), logged("f")
+	e.Hooks = &app.TaskHooks{Before: "f"}
+	f.DependsOn = []string{"e"}
+
+	workflow := app.CreateWorkflow(nil, &sync.Map{})
+	workflow.Tasks = []*app.Task{a, b, c, d, e, f}
+
+	for _, task := range workflow.Tasks {
+		task.Initialize(workflow)
+	}
+
+	run := func(task *app.Task) (bool, string) {
+		os.Remove(filepath.Join(dir, "hooks.log"))
+
+		done := make(chan bool, 1)
+		go func() { done <- task.RunSync() }()
+
+		select {
+		case result := <-done:
+			contents, _ := os.ReadFile(filepath.Join(dir, "hooks.log"))
+			return result, strings.Join(strings.Fields(string(contents)), " ")
+		case <-time.After(10 * time.Second):
+			t.Fatalf("running %s did not finish", task.Id)
+			return false, ""
+		}
+	}
+
+	result, log := run(a)
+	assert.True(t, result)
+	assert.Equal(t, "b d c a", log)
+
+	result, log = run(e)
+	assert.False(t, result)
+	assert.Equal(t, "", log)
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// TaskListItem describes a task defined in the workflow or one of its includes.
+type TaskListItem struct {
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	Source    string   `json:"source"`
+	Remote    bool     `json:"remote"`
+	Platforms []string `json:"platforms"`
+	UsedIn    []string `json:"used-in"`
+}
+
+// referencesTask returns true if any of `refs`, including the references in parallel groups, refer to `task`.
+func referencesTask(refs []*TaskReference, task *Task) bool {
+	for _, ref := range refs {
+		if ref.IsParallel() && referencesTask(ref.Parallel, task) {
+			return true
+		}
+
+		if !ref.IsParallel() && task.Id != "" && strings.EqualFold(ref.TaskId(), task.Id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetTaskUsage returns the names of the workflow sections that reference `task`.
+func (workflow *StackupWorkflow) GetTaskUsage(task *Task) []string {
+	result := []string{}
+	sections := []struct {
+		name string
+		refs []*TaskReference
+	}{
+		{"startup", workflow.Startup},
+		{"shutdown", workflow.Shutdown},
+		{"servers", workflow.Servers},
+	}
+
+	for _, section := range sections {
+		if referencesTask(section.refs, task) {
+			result = append(result, section.name)
+		}
+	}
+
+	for _, st := range workflow.Scheduler {
+		if task.Id != "" && strings.EqualFold(st.TaskId(), task.Id) {
+			result = append(result, "scheduler")
+			break
+		}
+	}
+
+	for _, wt := range workflow.Watchers {
+		if task.Id != "" && strings.EqualFold(wt.TaskId(), task.Id) {
+			result = append(result, "watchers")
+			break
+		}
+	}
+
+	if workflow.isHookTask(task) {
+		result = append(result, "hooks")
+	}
+
+	return result
+}
+
+// ListTasks returns a description of every task defined in the workflow and its includes.  `configFilename`
+// is used as the source of tasks that were not included from another file.
+func (workflow *StackupWorkflow) ListTasks(configFilename string) []TaskListItem {
+	result := []TaskListItem{}
+
+	for _, task := range workflow.Tasks {
+		platforms := task.Platforms
+		if platforms == nil {
+			platforms = []string{}
+		}
+
+		source := task.IncludedFrom
+		if source == "" {
+			source = configFilename
+		}
+
+		result = append(result, TaskListItem{
+			Id:        task.Id,
+			Name:      task.Name,
+			Source:    source,
+			Remote:    task.FromRemote,
+			Platforms: platforms,
+			UsedIn:    workflow.GetTaskUsage(task),
+		})
+	}
+
+	return result
+}
+
+func joinOrDefault(items []string, defaultValue string) string {
+	if len(items) == 0 {
+		return defaultValue
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// writes the workflow's tasks to `w` as a table, or as a JSON array if `asJson` is true.
+func (a *Application) listTasks(w io.Writer, asJson bool) error {
+	items := a.Workflow.ListTasks(a.ConfigFilename)
+
+	if asJson {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(items)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSOURCE\tPLATFORMS\tUSED IN")
+
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			item.Id,
+			item.Name,
+			item.Source,
+			joinOrDefault(item.Platforms, "all"),
+			joinOrDefault(item.UsedIn, "-"),
+		)
+	}
+
+	return tw.Flush()
+}
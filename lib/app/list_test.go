@@ -0,0 +1,36 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTasks(t *testing.T) {
+	workflow := app.CreateWorkflow(nil, nil)
+	workflow.Tasks = []*app.Task{
+		{Id: "start-containers", Name: "start containers"},
+		{Id: "httpd", Platforms: []string{"linux"}, IncludedFrom: "example.com/tasks.yaml", FromRemote: true},
+		{Id: "unused"},
+	}
+	workflow.Startup = []*app.TaskReference{{Parallel: []*app.TaskReference{{Task: "start-containers"}, {Task: "httpd"}}}}
+	workflow.Servers = []*app.TaskReference{{Task: "httpd"}}
+	workflow.Scheduler = []*app.ScheduledTask{{Task: "httpd", Cron: "* * * * *"}}
+
+	items := workflow.ListTasks("stackup.yaml")
+
+	assert.Equal(t, app.TaskListItem{
+		Id:        "start-containers",
+		Name:      "start containers",
+		Source:    "stackup.yaml",
+		Platforms: []string{},
+		UsedIn:    []string{"startup"},
+	}, items[0])
+
+	assert.Equal(t, "example.com/tasks.yaml", items[1].Source)
+	assert.True(t, items[1].Remote)
+	assert.Equal(t, []string{"linux"}, items[1].Platforms)
+	assert.Equal(t, []string{"startup", "servers", "scheduler"}, items[1].UsedIn)
+	assert.Equal(t, []string{}, items[2].UsedIn)
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"bytes"
+	"sync"
+)
+
+// logBuffer is an io.Writer that keeps the most recent lines written to it.
+type logBuffer struct {
+	lines   []string
+	partial []byte
+	max     int
+	lock    sync.Mutex
+}
+
+func newLogBuffer(max int) *logBuffer {
+	return &logBuffer{lines: []string{}, max: max}
+}
+
+func (lb *logBuffer) Write(p []byte) (int, error) {
+	lb.lock.Lock()
+	defer lb.lock.Unlock()
+
+	lb.partial = append(lb.partial, p...)
+
+	for {
+		index := bytes.IndexByte(lb.partial, '\n')
+		if index == -1 {
+			break
+		}
+
+		lb.lines = append(lb.lines, string(bytes.TrimSuffix(lb.partial[:index], []byte("\r"))))
+		lb.partial = lb.partial[index+1:]
+	}
+
+	if len(lb.lines) > lb.max {
+		lb.lines = append([]string{}, lb.lines[len(lb.lines)-lb.max:]...)
+	}
+
+	return len(p), nil
+}
+
+// Lines returns up to `count` of the most recent lines, including a final incomplete line.
+func (lb *logBuffer) Lines(count int) []string {
+	lb.lock.Lock()
+	defer lb.lock.Unlock()
+
+	result := append([]string{}, lb.lines...)
+	if len(lb.partial) > 0 {
+		result = append(result, string(lb.partial))
+	}
+
+	if count >= 0 && len(result) > count {
+		result = result[len(result)-count:]
+	}
+
+	return result
+}
@@ -0,0 +1,283 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/consts"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/notifications"
+	"github.com/stackup-app/stackup/lib/settings"
+	"github.com/stackup-app/stackup/lib/support"
+	"github.com/stackup-app/stackup/lib/utils"
+)
+
+// notification rules can be triggered by these events in addition to lifecycle events: `task.failed` is a
+// run of a task that failed or timed out, and `task.slow` is a task that is still running after the rule's
+// duration.
+const (
+	RuleEventTaskFailed = "task.failed"
+	RuleEventTaskSlow   = "task.slow"
+)
+
+// the events that notification rules can be triggered by, and the message that is sent if a rule does not
+// define one
+var notificationRuleMessages = map[string]string{
+	RuleEventTaskFailed:            "{name} failed with exit code {exit-code} ({status}).",
+	RuleEventTaskSlow:              "{name} has been running for more than {duration}.",
+	events.TaskFinished:            "{name} finished with exit code {exit-code} ({status}).",
+	events.ServerExited:            "{name} exited unexpectedly with exit code {exit-code}.",
+	events.ServerRestarting:        "{name} is restarting: {message}",
+	events.ServerNotReady:          "{message}",
+	events.PreconditionFailed:      "Precondition failed: {name}",
+	events.IncludeFailed:           "The include {name} could not be loaded: {message}",
+	events.IncludeChecksumMismatch: "The checksum of the include {name} does not match.",
+	events.WorkflowReloadFailed:    "The configuration was not reloaded: {message}",
+}
+
+var notificationChannels = []string{"desktop", "email", "slack", "telegram", "webhook"}
+
+// notificationRuleEvents returns the names of the events that notification rules can be triggered by.
+func notificationRuleEvents() []string {
+	result := []string{}
+	for name := range notificationRuleMessages {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// notificationRules sends notifications for lifecycle events, using the rules in the workflow's settings
+// when each event occurs so that changes to the rules are used after the workflow is reloaded.
+type notificationRules struct {
+	app         *Application
+	slowTimers  map[string]*time.Timer
+	dispatcher  *notifications.Dispatcher
+	unsubscribe func()
+	lock        sync.Mutex
+}
+
+// StartNotificationRules sends a notification whenever an event matches one of the rules in the
+// `settings.notifications.rules` section, until StopNotificationRules is called.
+func (a *Application) StartNotificationRules() {
+	a.notificationLock.Lock()
+	defer a.notificationLock.Unlock()
+
+	if a.notificationRules != nil {
+		return
+	}
+
+	nr := &notificationRules{app: a, slowTimers: map[string]*time.Timer{}}
+	nr.dispatcher = notifications.NewDispatcher(notifications.DefaultDispatcherOptions(), nr.handleDelivery)
+	nr.unsubscribe = events.Subscribe(nr.handleEvent)
+
+	a.notificationRules = nr
+}
+
+// StopNotificationRules stops sending notifications for events, and waits for the notifications that are
+// being sent to be delivered, for up to a few seconds.  It can be called from several goroutines as the
+// application exits; each call returns once the notifications have been delivered.
+func (a *Application) StopNotificationRules() {
+	a.notificationLock.Lock()
+	defer a.notificationLock.Unlock()
+
+	nr := a.notificationRules
+	if nr == nil {
+		return
+	}
+
+	a.notificationRules = nil
+	nr.unsubscribe()
+
+	nr.lock.Lock()
+	for _, timer := range nr.slowTimers {
+		timer.Stop()
+	}
+	nr.lock.Unlock()
+
+	nr.dispatcher.Stop(consts.NOTIFICATION_DELIVERY_TIMEOUT_SECONDS * time.Second)
+}
+
+func (nr *notificationRules) getRules() []settings.WorkflowSettingsNotificationRule {
+	workflow := nr.app.currentWorkflow()
+	if workflow == nil || workflow.Settings == nil {
+		return nil
+	}
+
+	return workflow.Settings.Notifications.Rules
+}
+
+func (nr *notificationRules) handleEvent(event events.Event) {
+	if event.Type == events.TaskOutput {
+		return
+	}
+
+	for i, rule := range nr.getRules() {
+		if !ruleMatchesTask(rule, event) {
+			continue
+		}
+
+		switch on := strings.ToLower(strings.TrimSpace(rule.On)); {
+		case on == RuleEventTaskSlow:
+			nr.handleSlowTask(i, rule, event)
+		case on == RuleEventTaskFailed:
+			if event.Type == events.TaskFinished && event.Status != "success" {
+				nr.send(rule, on, event)
+			}
+		case on == event.Type:
+			nr.send(rule, on, event)
+		}
+	}
+}
+
+// ruleMatchesTask returns true if `rule` applies to every task, or `event` is for one of the rule's tasks.
+func ruleMatchesTask(rule settings.WorkflowSettingsNotificationRule, event events.Event) bool {
+	if len(rule.Tasks) == 0 {
+		return true
+	}
+
+	for _, id := range rule.Tasks {
+		if event.Task != "" && strings.EqualFold(id, event.Task) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleSlowTask starts a timer when a task starts, which sends a notification if the task is still running
+// after the rule's duration, and stops it when the task finishes.
+func (nr *notificationRules) handleSlowTask(index int, rule settings.WorkflowSettingsNotificationRule, event events.Event) {
+	if event.Type != events.TaskStarted && event.Type != events.TaskFinished && event.Type != events.TaskSkipped {
+		return
+	}
+
+	key := fmt.Sprintf("%d:%s", index, strings.ToLower(event.Task))
+
+	nr.lock.Lock()
+	defer nr.lock.Unlock()
+
+	if timer, found := nr.slowTimers[key]; found {
+		timer.Stop()
+		delete(nr.slowTimers, key)
+	}
+
+	duration, err := time.ParseDuration(rule.Duration)
+	if event.Type != events.TaskStarted || err != nil {
+		return
+	}
+
+	slow := events.Event{Type: RuleEventTaskSlow, Task: event.Task, Name: event.Name}.WithDuration(duration)
+
+	nr.slowTimers[key] = time.AfterFunc(duration, func() {
+		nr.lock.Lock()
+		delete(nr.slowTimers, key)
+		nr.lock.Unlock()
+
+		nr.send(rule, RuleEventTaskSlow, slow)
+	})
+}
+
+// send queues the notification for `rule` to be delivered in the background, so that slow integrations do
+// not delay the task or server that emitted the event.  Notifications that are the same as one sent recently
+// are skipped.
+func (nr *notificationRules) send(rule settings.WorkflowSettingsNotificationRule, on string, event events.Event) {
+	sender, err := nr.app.newNotificationSender(rule.Channel, rule.To)
+	if err != nil {
+		support.FailureMessageWithXMark(messages.NotificationFailed(rule.Channel, err.Error()))
+		return
+	}
+
+	workflowName := nr.app.currentWorkflow().Name
+	if workflowName == "" {
+		workflowName = "StackUp"
+	}
+
+	title := formatNotificationTemplate(utils.FirstNonEmpty(rule.Title, "{workflow}"), event, workflowName)
+	message := formatNotificationTemplate(utils.FirstNonEmpty(rule.Message, notificationRuleMessages[on]), event, workflowName)
+
+	err = nr.dispatcher.Dispatch(strings.ToLower(rule.Channel), sender, title, message)
+	if err != nil && !errors.Is(err, notifications.ErrDuplicate) {
+		support.FailureMessageWithXMark(messages.NotificationFailed(rule.Channel, err.Error()))
+	}
+}
+
+// handleDelivery reports the result of sending a notification, once it has been sent or every attempt to
+// send it has failed.
+func (nr *notificationRules) handleDelivery(channel string, attempts int, err error) {
+	if err == nil {
+		events.Emit(events.Event{Type: events.NotificationSent, Name: channel, Attempt: attempts})
+		return
+	}
+
+	support.FailureMessageWithXMark(messages.NotificationFailed(channel, messages.NotificationAttempts(err.Error(), attempts)))
+	events.Emit(events.Event{Type: events.NotificationFailed, Name: channel, Attempt: attempts, Message: err.Error()})
+}
+
+// formatNotificationTemplate replaces the placeholders in `template` with the values of `event`.
+func formatNotificationTemplate(template string, event events.Event, workflowName string) string {
+	exitCode, duration := "", ""
+
+	if event.ExitCode != nil {
+		exitCode = fmt.Sprintf("%d", *event.ExitCode)
+	}
+	if event.DurationMs != nil {
+		duration = (time.Duration(*event.DurationMs) * time.Millisecond).String()
+	}
+
+	return strings.NewReplacer(
+		"{event}", event.Type,
+		"{task}", event.Task,
+		"{name}", event.Name,
+		"{status}", event.Status,
+		"{exit-code}", exitCode,
+		"{duration}", duration,
+		"{message}", event.Message,
+		"{workflow}", workflowName,
+	).Replace(template)
+}
+
+// newNotificationSender returns a sender for the integration `channel`, which sends to `recipients` or to
+// the recipients configured for the integration if there are none.  Webhooks do not have recipients.
+func (a *Application) newNotificationSender(channel string, recipients []string) (notifications.Sender, error) {
+	config := a.currentWorkflow().Settings.Notifications
+
+	switch strings.ToLower(channel) {
+	case "telegram":
+		if len(recipients) == 0 {
+			recipients = config.Telegram.ChatIds
+		}
+
+		chatIds, err := notifications.ParseChatIds(recipients)
+		if err != nil {
+			return nil, err
+		}
+
+		return notifications.NewTelegramNotificationFromSettings(config.Telegram, chatIds...), nil
+	case "slack":
+		if len(recipients) == 0 {
+			recipients = config.Slack.ChannelIds
+		}
+
+		return notifications.NewSlackNotification(config.Slack.WebhookUrl, recipients...), nil
+	case "email":
+		if len(recipients) == 0 {
+			recipients = config.Email.To
+		}
+
+		return notifications.NewEmailNotificationFromSettings(config.Email, a.Gateway, recipients...), nil
+	case "webhook":
+		return notifications.NewWebhookNotificationFromSettings(config.Webhook, a.Gateway), nil
+	case "desktop":
+		return notifications.NewDesktopNotification(a.GetApplicationIconPath()), nil
+	}
+
+	return nil, errors.New(messages.NotificationUnknownChannel(channel, notificationChannels))
+}
@@ -0,0 +1,89 @@
+package app_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationRulesSendMatchingEvents(t *testing.T) {
+	var lock sync.Mutex
+	received := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text string `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		lock.Lock()
+		received = append(received, payload.Text)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	a := app.NewApplication()
+	a.Workflow = app.CreateWorkflow(nil, &sync.Map{})
+	a.Workflow.Name = "rules test"
+	a.Workflow.Settings.Notifications.Slack.WebhookUrl = server.URL
+	a.Workflow.Settings.Notifications.Slack.ChannelIds = []string{"#builds"}
+	a.Workflow.Settings.Notifications.Rules = []settings.WorkflowSettingsNotificationRule{
+		{On: "task.failed", Tasks: []string{"build"}, Channel: "slack", Message: "{task} failed with {exit-code}"},
+		{On: "task.slow", Tasks: []string{"deploy"}, Channel: "slack", Title: "{workflow}", Duration: "50ms"},
+	}
+
+	var sent sync.WaitGroup
+	sent.Add(2)
+
+	unsubscribe := events.Subscribe(func(event events.Event) {
+		if event.Type == events.NotificationSent {
+			sent.Done()
+		}
+	})
+	defer unsubscribe()
+
+	a.StartNotificationRules()
+
+	events.Emit(events.Event{Type: events.TaskFinished, Task: "lint", Name: "lint", Status: "failed"}.WithExitCode(1))
+	events.Emit(events.Event{Type: events.TaskFinished, Task: "build", Name: "build", Status: "success"}.WithExitCode(0))
+	events.Emit(events.Event{Type: events.TaskFinished, Task: "build", Name: "build", Status: "failed"}.WithExitCode(2))
+	// the same notification is only sent once
+	events.Emit(events.Event{Type: events.TaskFinished, Task: "build", Name: "build", Status: "failed"}.WithExitCode(2))
+
+	// only the run of deploy that takes longer than the duration is reported
+	events.Emit(events.Event{Type: events.TaskStarted, Task: "deploy", Name: "deploy"})
+	events.Emit(events.Event{Type: events.TaskFinished, Task: "deploy", Name: "deploy", Status: "success"})
+	events.Emit(events.Event{Type: events.TaskStarted, Task: "deploy", Name: "deploy"})
+	time.Sleep(200 * time.Millisecond)
+
+	// the rules can be stopped from several goroutines at once as the application exits
+	var stopped sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		stopped.Add(1)
+		go func() {
+			defer stopped.Done()
+			a.StopNotificationRules()
+		}()
+	}
+	stopped.Wait()
+	sent.Wait()
+
+	// events are no longer handled once the rules are stopped
+	events.Emit(events.Event{Type: events.TaskFinished, Task: "build", Name: "build", Status: "failed"}.WithExitCode(3))
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.ElementsMatch(t, []string{
+		"build failed with 2",
+		"deploy has been running for more than 50ms.",
+	}, received)
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+	"github.com/stackup-app/stackup/lib/utils"
+)
+
+const (
+	OutputInherit  = "inherit"
+	OutputPrefixed = "prefixed"
+	OutputFile     = "file"
+	OutputSilent   = "silent"
+)
+
+type outputFlusher interface {
+	Flush() error
+}
+
+// taskOutput contains the writers that a task's command output is sent to.  If both writers are nil, the
+// output is discarded.
+type taskOutput struct {
+	Stdout   io.Writer
+	Stderr   io.Writer
+	flushers []outputFlusher
+	logFile  *os.File
+}
+
+// returns the task's output mode, or `defaultMode` if the task does not have a valid output mode.
+func (task *Task) getOutputMode(defaultMode string) string {
+	mode := strings.ToLower(strings.TrimSpace(task.Output))
+
+	switch mode {
+	case OutputInherit, OutputPrefixed, OutputFile, OutputSilent:
+		return mode
+	case "":
+		return defaultMode
+	}
+
+	support.WarningMessage(messages.TaskInvalidOutputMode(task.GetDisplayName(), task.Output))
+
+	return defaultMode
+}
+
+// returns the name used to prefix the task's output.
+func (task *Task) getOutputPrefix() string {
+	return utils.FirstNonEmpty(task.Id, task.GetDisplayName())
+}
+
+// returns the output mode used when the task is run synchronously and does not specify one.
+func (task *Task) getDefaultOutputMode() string {
+	if task.Silent {
+		return OutputSilent
+	}
+
+	return OutputInherit
+}
+
+// opens the task's `log-file` for appending, creating it if it does not exist.
+func (task *Task) openLogFile() *os.File {
+	if task.LogFile == "" {
+		return nil
+	}
+
+	filename := os.ExpandEnv(task.LogFile)
+	if task.JsEngine.IsEvaluatableScriptString(filename) {
+		filename = task.JsEngine.Evaluate(filename).(string)
+	}
+
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(task.Path, filename)
+	}
+
+	os.MkdirAll(filepath.Dir(filename), 0755)
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		support.WarningMessage(messages.TaskLogFileNotOpened(task.GetDisplayName(), filename))
+		return nil
+	}
+
+	return file
+}
+
+// openOutput creates the writers for the task's command output based on its output mode, using
+// `defaultMode` if the task does not specify one.  Output that is displayed is written to `stdout` and
+// `stderr`, emitted as events if the workflow's output is JSON, or only kept in the task's logs while the
+// dashboard is displayed; output is also written to the task's `log-file` unless the output mode is "silent".
+func (task *Task) openOutput(defaultMode string, stdout io.Writer, stderr io.Writer) *taskOutput {
+	mode := task.getOutputMode(defaultMode)
+	result := &taskOutput{}
+
+	if (mode == OutputInherit || mode == OutputPrefixed) && task.Workflow != nil && task.Workflow.JsonOutput {
+		eventsOut := events.NewOutputWriter(task.getOutputPrefix(), "stdout")
+		eventsErr := events.NewOutputWriter(task.getOutputPrefix(), "stderr")
+		result.flushers = []outputFlusher{eventsOut, eventsErr}
+		stdout, stderr, mode = eventsOut, eventsErr, OutputInherit
+	}
+
+	keepLogs := mode != OutputInherit
+
+	if (mode == OutputInherit || mode == OutputPrefixed) && task.Workflow != nil && task.Workflow.CaptureOutput {
+		stdout, stderr, mode, keepLogs = io.Discard, io.Discard, OutputInherit, true
+	}
+
+	switch mode {
+	case OutputInherit:
+		result.Stdout, result.Stderr = stdout, stderr
+	case OutputPrefixed:
+		prefixedOut := support.NewPrefixedWriter(stdout, task.getOutputPrefix())
+		prefixedErr := support.NewPrefixedWriter(stderr, task.getOutputPrefix())
+		result.flushers = []outputFlusher{prefixedOut, prefixedErr}
+		result.Stdout, result.Stderr = prefixedOut, prefixedErr
+	case OutputFile:
+		if task.LogFile == "" {
+			support.WarningMessage(messages.TaskLogFileRequired(task.GetDisplayName()))
+		}
+	case OutputSilent:
+		return result
+	}
+
+	if result.logFile = task.openLogFile(); result.logFile != nil && mode == OutputFile {
+		result.Stdout, result.Stderr = result.logFile, result.logFile
+	} else if result.logFile != nil {
+		result.Stdout = io.MultiWriter(result.Stdout, result.logFile)
+		result.Stderr = io.MultiWriter(result.Stderr, result.logFile)
+	}
+
+	// keep the most recent output so that it can be retrieved with the control API.  Output that is
+	// inherited is not kept, so that commands can still detect that they are writing to a terminal.
+	if task.logs != nil && keepLogs {
+		stdoutLogs := appendWriter(result.Stdout, task.logs)
+		stderrLogs := stdoutLogs
+
+		// writing both streams to the same writer keeps their output in order
+		if result.Stderr != result.Stdout {
+			stderrLogs = appendWriter(result.Stderr, task.logs)
+		}
+
+		result.Stdout, result.Stderr = stdoutLogs, stderrLogs
+	}
+
+	return result
+}
+
+// appendWriter returns a writer that writes to both `w` and `extra`, or only to `extra` if `w` is nil.
+func appendWriter(w io.Writer, extra io.Writer) io.Writer {
+	if w == nil {
+		return extra
+	}
+
+	return io.MultiWriter(w, extra)
+}
+
+// apply sets the output writers of `opts`.
+func (output *taskOutput) apply(opts *utils.CommandOptions) {
+	opts.Stdout = output.Stdout
+	opts.Stderr = output.Stderr
+	opts.Silent = output.Stdout == nil && output.Stderr == nil
+}
+
+// Flush writes any incomplete lines of prefixed output or output events.
+func (output *taskOutput) Flush() {
+	for _, w := range output.flushers {
+		w.Flush()
+	}
+}
+
+// Close flushes the output and closes the log file.
+func (output *taskOutput) Close() {
+	output.Flush()
+
+	if output.logFile != nil {
+		output.logFile.Close()
+	}
+}
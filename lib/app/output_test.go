@@ -0,0 +1,56 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSyncWritesOutputToLogFile(t *testing.T) {
+	requirePosixShell(t)
+
+	dir := t.TempDir()
+	task := &app.Task{Id: "build", Command: "sh -c 'echo one; echo two >&2'", Path: dir, Output: "file", LogFile: "logs/build.log"}
+
+	workflow := app.CreateWorkflow(nil, &sync.Map{})
+	workflow.Tasks = []*app.Task{task}
+	task.Initialize(workflow)
+
+	assert.True(t, task.RunSync())
+
+	contents, err := os.ReadFile(filepath.Join(dir, "logs", "build.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(contents))
+}
+
+func TestRunSyncEmitsEventsWithJsonOutput(t *testing.T) {
+	requirePosixShell(t)
+
+	task := &app.Task{Id: "build", Command: "sh -c 'echo one; exit 3'", Path: t.TempDir()}
+
+	workflow := app.CreateWorkflow(nil, &sync.Map{})
+	workflow.Tasks = []*app.Task{task}
+	workflow.JsonOutput = true
+	task.Initialize(workflow)
+
+	received := []events.Event{}
+	unsubscribe := events.Subscribe(func(event events.Event) {
+		received = append(received, event)
+	})
+	defer unsubscribe()
+
+	assert.False(t, task.RunSync())
+
+	assert.Len(t, received, 3)
+	assert.Equal(t, events.TaskStarted, received[0].Type)
+	assert.Equal(t, events.Event{Type: events.TaskOutput, Time: received[1].Time, Task: "build", Stream: "stdout", Line: "one"}, received[1])
+	assert.Equal(t, events.TaskFinished, received[2].Type)
+	assert.Equal(t, "failed", received[2].Status)
+	assert.Equal(t, 3, *received[2].ExitCode)
+	assert.NotNil(t, received[2].DurationMs)
+}
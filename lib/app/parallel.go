@@ -0,0 +1,158 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+	"github.com/stackup-app/stackup/lib/utils"
+)
+
+// bufferedTaskRun is a single run of a task whose output is buffered so that it can be displayed
+// once the task has completed, without interleaving with the output of other tasks.
+type bufferedTaskRun struct {
+	task    *Task
+	command string
+	canRun  bool
+	skipped string
+	success bool
+	err     error
+	output  bytes.Buffer
+	writers *taskOutput
+	cleanup func()
+}
+
+// newBufferedRun evaluates the task's conditions and command.  This must happen before the run is
+// executed, since the javascript engine cannot be used from multiple goroutines at once.
+func (task *Task) newBufferedRun() *bufferedTaskRun {
+	result := &bufferedTaskRun{task: task}
+
+	if !task.runDependencies() {
+		result.skipped = messages.TaskDependencyFailed(task.GetDisplayName(), strings.Join(task.DependsOn, ", "))
+		return result
+	}
+
+	result.canRun, result.skipped, result.cleanup = task.beginRun()
+
+	if result.canRun && !task.runBeforeHook() {
+		result.canRun, result.skipped = false, messages.TaskHookFailed(task.GetDisplayName(), "before")
+		task.exitCode = 1
+		result.cleanup()
+	}
+
+	if result.canRun {
+		result.command = task.getCommand()
+		result.writers = task.openOutput(task.getDefaultOutputMode(), &result.output, &result.output)
+	}
+
+	return result
+}
+
+func (run *bufferedTaskRun) execute() {
+	defer run.writers.Close()
+
+	opts := run.task.commandOptions(run.writers)
+	startedAt := time.Now()
+	events.Emit(run.task.newEvent(events.TaskStarted, ""))
+
+	run.err = run.task.executeWithRetries(run.command, opts, func(attempt int, delay time.Duration) {
+		message := messages.TaskRetrying(run.task.GetDisplayName(), attempt, run.task.Retries, delay)
+		run.task.emitRetrying(attempt, message)
+
+		// the buffered output is not displayed when the output is JSON
+		if run.task.Workflow.JsonOutput {
+			support.WarningMessage(message)
+			return
+		}
+
+		fmt.Fprintln(&run.output, support.MessageIndentation+message)
+	})
+	run.success = run.err == nil
+	run.task.exitCode = utils.ExitCode(run.err)
+	run.task.Workflow.State.SetCompleted(run.task, run.success)
+
+	run.writers.Flush()
+	run.task.emitFinished(run.err, startedAt)
+}
+
+// report displays the result of the run followed by its buffered output, then runs the task's hooks.
+func (run *bufferedTaskRun) report() {
+	if !run.canRun {
+		support.SkippedMessageWithSymbol(run.skipped)
+		events.Emit(run.task.newEvent(events.TaskSkipped, run.skipped))
+		return
+	}
+
+	if run.success {
+		support.SuccessMessageWithCheck(run.task.GetDisplayName())
+	} else if errors.Is(run.err, utils.ErrCommandTimedOut) {
+		support.FailureMessageWithXMark(messages.TaskTimedOut(run.task.GetDisplayName(), run.task.Timeout))
+	} else {
+		support.FailureMessageWithXMark(run.task.GetDisplayName())
+	}
+
+	// output is emitted as events while the task runs when the output is JSON
+	if !run.task.Workflow.JsonOutput {
+		os.Stdout.Write(run.output.Bytes())
+	}
+
+	run.task.runCompletionHooks(run.success)
+}
+
+// runParallelTaskReferences runs all of the referenced tasks at the same time and waits for all of them
+// to complete.  Dependencies are run first, one at a time.
+func (a *Application) runParallelTaskReferences(refs []*TaskReference) {
+	runs := []*bufferedTaskRun{}
+	names := []string{}
+
+	for _, def := range refs {
+		def.Workflow = a.Workflow
+		def.JsEngine = a.JsEngine
+
+		task, found := a.Workflow.GetTaskById(def.TaskId())
+		if !found {
+			support.SkippedMessageWithSymbol(messages.TaskNotFound(def.TaskId()))
+			continue
+		}
+
+		runs = append(runs, task.newBufferedRun())
+		names = append(names, task.GetDisplayName())
+	}
+
+	support.StatusMessageLine(messages.RunningInParallel(names), false)
+
+	var wg sync.WaitGroup
+
+	for _, run := range runs {
+		if !run.canRun {
+			continue
+		}
+
+		wg.Add(1)
+		go func(r *bufferedTaskRun) {
+			defer wg.Done()
+			r.execute()
+		}(run)
+	}
+
+	wg.Wait()
+
+	// the workflow state is restored in the reverse order that the runs were started in, once none of
+	// them are still running
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].canRun && runs[i].cleanup != nil {
+			runs[i].cleanup()
+		}
+	}
+
+	for _, run := range runs {
+		run.report()
+	}
+}
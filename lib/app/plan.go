@@ -0,0 +1,257 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// the number of upcoming run times displayed for each scheduled task
+const planScheduleRunCount = 3
+
+// planner writes a description of what running the workflow would do, without running any commands.
+type planner struct {
+	app     *Application
+	w       io.Writer
+	now     time.Time
+	planned map[*Task]bool
+}
+
+// PrintPlan writes what each stage of the workflow would run to `w`.  Scripts other than the init script
+// are evaluated so that commands, paths and conditions can be displayed with their resolved values, but
+// script functions with side effects, such as running commands, writing files or sending notifications,
+// do nothing.  `now` is used to calculate when scheduled tasks would next run.
+func (a *Application) PrintPlan(w io.Writer, now time.Time) {
+	p := &planner{app: a, w: w, now: now, planned: map[*Task]bool{}}
+
+	fmt.Fprintln(w, "Dry run: no commands will be run.")
+
+	p.section("Init script")
+	p.planInitScript()
+
+	p.section("Preconditions")
+	p.planPreconditions()
+
+	p.section("Startup tasks")
+	p.planTaskReferences(a.Workflow.Startup, "  ")
+
+	p.section("Servers")
+	p.planTaskReferences(a.Workflow.Servers, "  ")
+
+	p.section("Scheduled tasks")
+	p.planScheduledTasks()
+
+	p.section("File watchers")
+	p.planWatchers()
+
+	p.section("Shutdown tasks")
+	p.planTaskReferences(a.Workflow.Shutdown, "  ")
+
+	p.section("Workflow hooks")
+	p.planWorkflowHooks()
+}
+
+func (p *planner) section(name string) {
+	fmt.Fprintf(p.w, "\n%s:\n", name)
+}
+
+func (p *planner) line(indent string, format string, args ...any) {
+	fmt.Fprintf(p.w, indent+format+"\n", args...)
+}
+
+// the init script is not evaluated, since it can do anything that a script can, such as writing files or
+// sending notifications
+func (p *planner) planInitScript() {
+	if strings.TrimSpace(p.app.Workflow.Init) == "" {
+		p.line("  ", "none")
+		return
+	}
+
+	p.line("  ", "not evaluated during a dry run")
+}
+
+func (p *planner) planPreconditions() {
+	if len(p.app.Workflow.Preconditions) == 0 {
+		p.line("  ", "none")
+		return
+	}
+
+	for _, c := range p.app.Workflow.Preconditions {
+		if c.evaluateCheck() {
+			p.line("  ", "✓ %s", c.Name)
+			continue
+		}
+
+		p.line("  ", "✗ %s", c.Name)
+
+		switch {
+		case c.OnFail == "":
+			p.line("      ", "on failure: exit")
+		case c.JsEngine.IsEvaluatableScriptString(c.OnFail):
+			p.line("      ", "on failure: evaluate %s", c.OnFail)
+		default:
+			p.line("      ", "on failure: run task '%s'", c.OnFail)
+		}
+	}
+}
+
+func (p *planner) planTaskReferences(refs []*TaskReference, indent string) {
+	if len(refs) == 0 {
+		p.line(indent, "none")
+		return
+	}
+
+	for _, ref := range refs {
+		ref.Workflow = p.app.Workflow
+		ref.JsEngine = p.app.JsEngine
+
+		if ref.IsParallel() {
+			p.line(indent, "in parallel:")
+			p.planTaskReferences(ref.Parallel, indent+"  ")
+			continue
+		}
+
+		task, found := p.app.Workflow.GetTaskById(ref.TaskId())
+		if !found {
+			p.line(indent, "✗ task '%s' not found", ref.TaskId())
+			continue
+		}
+
+		p.planTask(task, indent)
+	}
+}
+
+// planTask describes the task's dependencies, which only run once, followed by the task itself.
+func (p *planner) planTask(task *Task, indent string) {
+	if len(task.DependsOn) > 0 {
+		dependencies, err := p.app.Workflow.ResolveDependencies(task)
+		if err != nil {
+			p.line(indent, "✗ %s", err.Error())
+			return
+		}
+
+		for _, dependency := range dependencies {
+			if !p.planned[dependency] {
+				p.planned[dependency] = true
+				p.planTask(dependency, indent)
+			}
+		}
+	}
+
+	task.resolvePath()
+
+	if !task.canRunConditionally() {
+		p.line(indent, "↷ %s: skipped, 'if' condition is false", task.GetDisplayName())
+		return
+	}
+
+	if !task.canRunOnCurrentPlatform() {
+		p.line(indent, "↷ %s: skipped, not supported on %s (platforms: %s)", task.GetDisplayName(), runtime.GOOS, strings.Join(task.Platforms, ", "))
+		return
+	}
+
+	path, err := filepath.Abs(task.Path)
+	if err != nil {
+		path = task.Path
+	}
+
+	p.line(indent, "• %s", task.GetDisplayName())
+	p.line(indent+"    ", "command: %s", strings.ReplaceAll(strings.TrimSpace(task.getCommand()), "\n", "\n"+indent+"             "))
+	p.line(indent+"    ", "path:    %s", path)
+
+	if task.Shell != "" {
+		p.line(indent+"    ", "shell:   %s", task.Shell)
+	}
+
+	if task.Hooks != nil {
+		p.planHooks(indent+"    ", []string{"before", "on-success", "on-failure", "after"}, task.Hooks.values())
+	}
+}
+
+func (p *planner) planWorkflowHooks() {
+	hooks := p.app.Workflow.Hooks.values()
+
+	if strings.Join(hooks, "") == "" {
+		p.line("  ", "none")
+		return
+	}
+
+	p.planHooks("  ", []string{"on-start", "on-ready", "on-shutdown", "on-error"}, hooks)
+}
+
+// planHooks describes the hooks that are set, where `hooks` are the values of the hooks named `names`.
+func (p *planner) planHooks(indent string, names []string, hooks []string) {
+	for i, hook := range hooks {
+		switch {
+		case hook == "":
+			continue
+		case p.app.JsEngine.IsEvaluatableScriptString(hook):
+			p.line(indent, "%s: evaluate %s", names[i], hook)
+		default:
+			p.line(indent, "%s: run task '%s'", names[i], hook)
+		}
+	}
+}
+
+func (p *planner) planScheduledTasks() {
+	if len(p.app.Workflow.Scheduler) == 0 {
+		p.line("  ", "none")
+		return
+	}
+
+	for _, st := range p.app.Workflow.Scheduler {
+		st.Workflow = p.app.Workflow
+		st.JsEngine = p.app.JsEngine
+
+		task, found := p.app.Workflow.GetTaskById(st.TaskId())
+		if !found {
+			p.line("  ", "✗ task '%s' not found", st.TaskId())
+			continue
+		}
+
+		schedule, err := cron.ParseStandard(st.Cron)
+		if err != nil {
+			p.line("  ", "✗ %s: invalid cron expression '%s'", task.GetDisplayName(), st.Cron)
+			continue
+		}
+
+		times := []string{}
+		next := p.now
+
+		for i := 0; i < planScheduleRunCount; i++ {
+			next = schedule.Next(next)
+			times = append(times, next.Format("2006-01-02 15:04"))
+		}
+
+		p.line("  ", "• %s: '%s', next runs at %s", task.GetDisplayName(), st.Cron, strings.Join(times, ", "))
+	}
+}
+
+func (p *planner) planWatchers() {
+	if len(p.app.Workflow.Watchers) == 0 {
+		p.line("  ", "none")
+		return
+	}
+
+	for _, wt := range p.app.Workflow.Watchers {
+		wt.Workflow = p.app.Workflow
+		wt.JsEngine = p.app.JsEngine
+
+		task, found := p.app.Workflow.GetTaskById(wt.TaskId())
+		if !found {
+			p.line("  ", "✗ task '%s' not found", wt.TaskId())
+			continue
+		}
+
+		p.line("  ", "• %s: when %s change", task.GetDisplayName(), strings.Join(wt.Paths, ", "))
+
+		if len(wt.Ignore) > 0 {
+			p.line("    ", "ignoring %s", strings.Join(wt.Ignore, ", "))
+		}
+	}
+}
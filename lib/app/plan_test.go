@@ -0,0 +1,50 @@
+package app_test
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintPlan(t *testing.T) {
+	otherPlatform := "windows"
+	if runtime.GOOS == "windows" {
+		otherPlatform = "linux"
+	}
+
+	workflow := app.CreateWorkflow(nil, &sync.Map{})
+	workflow.Tasks = []*app.Task{
+		{Id: "install", Name: "install dependencies", Command: "npm install", Path: "/project"},
+		{Id: "build", Command: "npm run build", Path: "/project", DependsOn: []string{"install"}, Hooks: &app.TaskHooks{OnFailure: "install"}},
+		{Id: "other", Command: "echo other", Path: "/project", Platforms: []string{otherPlatform}},
+	}
+	workflow.Startup = []*app.TaskReference{{Task: "build"}, {Task: "other"}}
+	workflow.Scheduler = []*app.ScheduledTask{{Task: "build", Cron: "0 * * * *"}}
+	workflow.Watchers = []*app.WatchedTask{{Task: "install", Paths: []string{"package.json"}}}
+	workflow.Hooks = app.WorkflowHooks{OnReady: `{{ exec("open http://localhost:8000") }}`}
+	workflow.Init = `fs.writeFile("init.txt", "written")`
+
+	for _, task := range workflow.Tasks {
+		task.Initialize(workflow)
+	}
+
+	var buf bytes.Buffer
+	a := &app.Application{Workflow: workflow}
+	a.PrintPlan(&buf, time.Date(2023, 1, 1, 10, 30, 0, 0, time.UTC))
+
+	output := buf.String()
+
+	assert.Contains(t, output, "Init script:\n  not evaluated during a dry run\n")
+	assert.Contains(t, output, "• install dependencies\n      command: npm install\n      path:    /project\n  • build\n")
+	assert.Contains(t, output, "↷ other: skipped, not supported on "+runtime.GOOS)
+	assert.Contains(t, output, "• build: '0 * * * *', next runs at 2023-01-01 11:00, 2023-01-01 12:00, 2023-01-01 13:00")
+	assert.Contains(t, output, "File watchers:\n  • install dependencies: when package.json change\n")
+	assert.Contains(t, output, "  • build\n      command: npm run build\n      path:    /project\n      on-failure: run task 'install'\n")
+	assert.Contains(t, output, "Shutdown tasks:\n  none\n")
+	assert.Contains(t, output, "Workflow hooks:\n  on-ready: evaluate {{ exec(\"open http://localhost:8000\") }}\n")
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/consts"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+)
+
+// ReadyProbe defines the conditions that must all be met before a server is considered ready.  `Log` is a
+// regular expression matched against each line of the server's output, and `Script` is a javascript
+// expression that must evaluate to true.
+type ReadyProbe struct {
+	Port     int    `yaml:"port,omitempty"`
+	Host     string `yaml:"host,omitempty"`
+	Url      string `yaml:"url,omitempty"`
+	Log      string `yaml:"log,omitempty"`
+	Script   string `yaml:"script,omitempty"`
+	Timeout  string `yaml:"timeout,omitempty"`
+	Interval string `yaml:"interval,omitempty"`
+}
+
+// logMatcher is an io.Writer that checks each line written to it against a regular expression.
+type logMatcher struct {
+	pattern *regexp.Regexp
+	line    []byte
+	matched bool
+	lock    sync.Mutex
+}
+
+func newLogMatcher(pattern string) (*logMatcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logMatcher{pattern: re}, nil
+}
+
+func (lm *logMatcher) Write(p []byte) (int, error) {
+	lm.lock.Lock()
+	defer lm.lock.Unlock()
+
+	if lm.matched {
+		return len(p), nil
+	}
+
+	lm.line = append(lm.line, p...)
+
+	for {
+		index := bytes.IndexByte(lm.line, '\n')
+		if index == -1 {
+			break
+		}
+
+		if lm.pattern.Match(lm.line[:index]) {
+			lm.matched = true
+			lm.line = nil
+			break
+		}
+
+		lm.line = lm.line[index+1:]
+	}
+
+	return len(p), nil
+}
+
+// reset forgets any output written so far, so that only output from a new process can match.
+func (lm *logMatcher) reset() {
+	lm.lock.Lock()
+	defer lm.lock.Unlock()
+
+	lm.matched = false
+	lm.line = nil
+}
+
+func (lm *logMatcher) Matched() bool {
+	lm.lock.Lock()
+	defer lm.lock.Unlock()
+
+	return lm.matched || lm.pattern.Match(lm.line)
+}
+
+func (probe *ReadyProbe) evaluate(task *Task, value string) string {
+	if task.JsEngine.IsEvaluatableScriptString(value) {
+		return task.JsEngine.Evaluate(value).(string)
+	}
+
+	return value
+}
+
+func isPortOpen(host string, port int, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return false
+	}
+
+	conn.Close()
+
+	return true
+}
+
+func isUrlAvailable(url string, timeout time.Duration) bool {
+	client := http.Client{Timeout: timeout}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return false
+	}
+
+	resp.Body.Close()
+
+	return resp.StatusCode >= 200 && resp.StatusCode <= 299
+}
+
+// isReady returns true if all of the probe's conditions are met.
+func (sp *ServerProcess) isReady(host string, url string) bool {
+	probe := sp.Task.Ready
+
+	if probe.Port > 0 && !isPortOpen(host, probe.Port, time.Second) {
+		return false
+	}
+
+	if url != "" && !isUrlAvailable(url, 2*time.Second) {
+		return false
+	}
+
+	if sp.logMatcher != nil && !sp.logMatcher.Matched() {
+		return false
+	}
+
+	if probe.Script != "" {
+		return sp.Task.JsEngine.Evaluate(sp.Task.JsEngine.MakeStringEvaluatable(probe.Script)) == true
+	}
+
+	return true
+}
+
+// WaitUntilReady waits for the server's ready probe to succeed.  Returns false if the probe did not
+// succeed before its timeout, or if the server exited while waiting.
+func (sp *ServerProcess) WaitUntilReady() bool {
+	task := sp.Task
+	probe := task.Ready
+
+	if probe == nil {
+		return true
+	}
+
+	timeout := task.parseDuration("ready timeout", probe.Timeout, consts.DEFAULT_READY_TIMEOUT_SECONDS*time.Second)
+	interval := task.parseDuration("ready interval", probe.Interval, consts.DEFAULT_READY_INTERVAL_MS*time.Millisecond)
+	host := probe.evaluate(task, probe.Host)
+	url := probe.evaluate(task, probe.Url)
+
+	if host == "" {
+		host = "localhost"
+	}
+
+	support.StatusMessageLine(messages.ServerWaitingUntilReady(task.GetDisplayName()), false)
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if sp.isReady(host, url) {
+			support.SuccessMessageWithCheck(messages.ServerReady(task.GetDisplayName()))
+			events.Emit(task.newEvent(events.ServerReady, ""))
+			return true
+		}
+
+		state := sp.GetState()
+		if state != ServerRunning && state != ServerRestarting {
+			support.FailureMessageWithXMark(messages.ServerExitedBeforeReady(task.GetDisplayName()))
+			events.Emit(task.newEvent(events.ServerNotReady, messages.ServerExitedBeforeReady(task.GetDisplayName())))
+			return false
+		}
+
+		if time.Now().After(deadline) {
+			support.FailureMessageWithXMark(messages.ServerNotReady(task.GetDisplayName(), timeout))
+			events.Emit(task.newEvent(events.ServerNotReady, messages.ServerNotReady(task.GetDisplayName(), timeout)))
+			return false
+		}
+
+		time.Sleep(interval)
+	}
+}
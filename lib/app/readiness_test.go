@@ -0,0 +1,72 @@
+package app_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitUntilReadyMatchesLogOutput(t *testing.T) {
+	server, _ := superviseTask(t, &app.Task{
+		Id:      "server",
+		Command: "sh -c 'echo starting; echo listening on port 8000; exec sleep 5'",
+		Path:    ".",
+		Ready:   &app.ReadyProbe{Log: `listening on port \d+`, Timeout: "5s", Interval: "10ms"},
+	})
+	defer server.Cmd.Process.Kill()
+
+	assert.True(t, server.WaitUntilReady())
+}
+
+func TestWaitUntilReadyChecksPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	server, _ := superviseTask(t, &app.Task{
+		Id:      "server",
+		Command: "sleep 5",
+		Path:    ".",
+		Ready:   &app.ReadyProbe{Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port, Timeout: "5s", Interval: "10ms"},
+	})
+	defer server.Cmd.Process.Kill()
+
+	assert.True(t, server.WaitUntilReady())
+}
+
+func TestWaitUntilReadyFailsWhenServerExits(t *testing.T) {
+	server, _ := superviseTask(t, &app.Task{
+		Id:      "server",
+		Command: "sh -c 'exit 1'",
+		Path:    ".",
+		Ready:   &app.ReadyProbe{Log: "never printed", Timeout: "5s", Interval: "10ms"},
+	})
+
+	assert.False(t, server.WaitUntilReady())
+}
+
+func TestWaitUntilReadyIgnoresOutputFromBeforeARestart(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "started")
+	server, _ := superviseTask(t, &app.Task{
+		Id:           "server",
+		Command:      "sh -c 'if [ -f " + marker + " ]; then exec sleep 5; fi; touch " + marker + "; echo listening; exit 1'",
+		Path:         ".",
+		Restart:      "on-failure",
+		RestartDelay: "1ms",
+		Ready:        &app.ReadyProbe{Log: "listening", Timeout: "200ms", Interval: "10ms"},
+	})
+	defer func() { server.Cmd.Process.Kill() }()
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(marker)
+		return err == nil && server.GetState() == app.ServerRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	assert.False(t, server.WaitUntilReady())
+}
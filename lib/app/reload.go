@@ -0,0 +1,371 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stackup-app/stackup/lib/consts"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+	"github.com/stackup-app/stackup/lib/watcher"
+)
+
+// workflowChanges describes the differences between the running workflow and its reloaded configuration.
+type workflowChanges struct {
+	// tasks whose definitions changed, which are used the next time the tasks run
+	updated []*Task
+	// server tasks that are running and have changed, which are restarted
+	restarted []*Task
+	// server tasks that were added to `servers`, which are started
+	started []*Task
+	// servers that were removed from `servers`, which are stopped
+	stopped []*ServerProcess
+	// cron entries that were removed, and scheduled tasks that were added
+	unscheduled     []cron.EntryID
+	scheduled       []*ScheduledTask
+	initChanged     bool
+	watchersChanged bool
+}
+
+// summary returns a description of each change, for displaying after the changes are applied.
+func (c *workflowChanges) summary() []string {
+	result := []string{}
+
+	restarted := map[*Task]bool{}
+	for _, task := range c.restarted {
+		restarted[task] = true
+	}
+
+	for _, task := range c.updated {
+		if !restarted[task] {
+			result = append(result, "updated "+task.Id)
+		}
+	}
+	for _, task := range c.restarted {
+		result = append(result, "restarted "+task.Id)
+	}
+	for _, task := range c.started {
+		result = append(result, "started "+task.Id)
+	}
+	for _, server := range c.stopped {
+		result = append(result, "stopped "+server.Task.Id)
+	}
+	if len(c.unscheduled) > 0 || len(c.scheduled) > 0 {
+		result = append(result, fmt.Sprintf("scheduled %d and unscheduled %d task(s)", len(c.scheduled), len(c.unscheduled)))
+	}
+	if c.initChanged {
+		result = append(result, "ran init script")
+	}
+	if c.watchersChanged {
+		result = append(result, "restarted file watchers")
+	}
+
+	return result
+}
+
+// watchConfiguration reloads the workflow when the configuration file or one of its local includes changes,
+// unless hot reloading has been disabled.
+func (a *Application) watchConfiguration() {
+	if !a.Workflow.Settings.IsHotReloadEnabled() {
+		return
+	}
+
+	a.configWatcher = watcher.New(
+		consts.CONFIG_WATCH_INTERVAL_MS*time.Millisecond,
+		consts.CONFIG_WATCH_DEBOUNCE_MS*time.Millisecond,
+		func(changed []string) {
+			support.StatusMessageLine(messages.ConfigurationChanged(a.displayFilenames(changed)), true)
+
+			// reloading evaluates scripts, so it must run on the event loop
+			a.runOnEventLoop(func() { a.ReloadWorkflow() })
+		},
+	)
+
+	a.configWatcher.SetFiles(a.getConfigurationFilenames(a.Workflow))
+	a.configWatcher.Start()
+}
+
+func (a *Application) stopWatchingConfiguration() {
+	if a.configWatcher != nil {
+		a.configWatcher.Stop()
+	}
+}
+
+// getConfigurationFilenames returns the configuration file and the local files that `workflow` includes.
+func (a *Application) getConfigurationFilenames(workflow *StackupWorkflow) []string {
+	return append([]string{a.ConfigFilename}, workflow.getLocalIncludeFilenames()...)
+}
+
+// ReloadWorkflow loads the configuration again and applies the changes to the running workflow: changed
+// servers are restarted, added servers are started, removed servers are stopped, cron entries are added
+// and removed, file watchers are restarted if they changed, and the init script is run again if it
+// changed.  Startup tasks and preconditions are not run again.  If the configuration is not valid, the
+// running workflow is not changed and an error is returned.
+func (a *Application) ReloadWorkflow() error {
+	next, err := a.loadReloadedWorkflow()
+	if err != nil {
+		support.FailureMessageWithXMark(messages.ConfigurationReloadRejected(err.Error()))
+		events.Emit(events.Event{Type: events.WorkflowReloadFailed, Name: a.Workflow.Name, Message: err.Error()})
+		return err
+	}
+
+	changes := a.compareWorkflows(a.Workflow, next)
+
+	// servers are stopped before the workflow is replaced, since they were started from its definitions
+	for _, server := range append(append([]*ServerProcess{}, changes.stopped...), a.findServers(changes.restarted)...) {
+		a.Supervisor.StopServer(server, a.SignalCommandCallback, a.KillCommandCallback)
+	}
+	for _, server := range changes.stopped {
+		a.Supervisor.Remove(server)
+	}
+
+	a.setWorkflow(next)
+
+	if changes.initChanged {
+		a.JsEngine.Evaluate(next.Init)
+	}
+
+	for _, task := range append(append([]*Task{}, changes.restarted...), changes.started...) {
+		if server := task.RunAsync(); server != nil {
+			a.Supervisor.Supervise(server)
+		}
+	}
+
+	for _, id := range changes.unscheduled {
+		a.removeScheduledTask(id)
+	}
+	for _, def := range changes.scheduled {
+		a.addScheduledTask(def)
+	}
+
+	if changes.watchersChanged {
+		a.stopFileWatchers()
+		a.startFileWatchers()
+	}
+
+	if a.configWatcher != nil {
+		a.configWatcher.SetFiles(a.getConfigurationFilenames(next))
+	}
+
+	summary := changes.summary()
+	support.SuccessMessageWithCheck(messages.ConfigurationReloaded(summary))
+	events.Emit(events.Event{Type: events.WorkflowReloaded, Name: next.Name, Message: strings.Join(summary, ", ")})
+
+	return nil
+}
+
+// loadReloadedWorkflow loads and validates the configuration, and returns it as a new workflow.
+func (a *Application) loadReloadedWorkflow() (*StackupWorkflow, error) {
+	next := CreateWorkflow(a.Gateway, a.ProcessMap)
+
+	if err := a.loadWorkflowFile(a.ConfigFilename, next); err != nil {
+		return nil, err
+	}
+
+	// includes that fail checksum verification reject the new configuration instead of exiting
+	next.ExitAppFunc = nil
+
+	next.Cache = a.Workflow.Cache
+	next.JsonOutput = a.Workflow.JsonOutput
+	next.CaptureOutput = a.Workflow.CaptureOutput
+	next.Initialize(a.JsEngine, a.GetConfigurationPath())
+
+	failed := 0
+	for _, err := range validateWorkflowFile(a.ConfigFilename, next) {
+		if err.Warning {
+			support.WarningMessage(err.Error())
+			continue
+		}
+
+		support.FailureMessageWithXMark(err.Error())
+		failed++
+	}
+
+	if failed > 0 {
+		return nil, errors.New(messages.ConfigurationInvalid(failed))
+	}
+
+	if next.hasFailedIncludes() {
+		return nil, errors.New(messages.ConfigurationIncludeFailed())
+	}
+
+	return next, nil
+}
+
+// compareWorkflows returns the changes needed to run `next` instead of `current`.  Tasks whose definitions
+// have not changed are moved into `next`, so that running servers and the state of each task are kept;
+// tasks that have changed keep the state of their previous definition.
+func (a *Application) compareWorkflows(current *StackupWorkflow, next *StackupWorkflow) *workflowChanges {
+	result := &workflowChanges{
+		initChanged:     strings.TrimSpace(current.Init) != strings.TrimSpace(next.Init),
+		watchersChanged: !sameWatchers(current.Watchers, next.Watchers),
+	}
+	changed := map[string]bool{}
+
+	next.State = current.State
+
+	for i, task := range next.Tasks {
+		previous, found := current.GetTaskById(task.Id)
+		if !found {
+			continue
+		}
+
+		// a task's path is replaced by its evaluated value when the task runs
+		previous.resolvePath()
+		task.resolvePath()
+
+		if sameDefinition(previous, task) {
+			previous.Workflow = next
+			next.Tasks[i] = previous
+			continue
+		}
+
+		task.inheritState(previous)
+		changed[strings.ToLower(task.Id)] = true
+		result.updated = append(result.updated, task)
+	}
+
+	serverIds := map[string]bool{}
+	for _, ref := range next.Servers {
+		serverIds[strings.ToLower(ref.TaskId())] = true
+	}
+
+	a.Supervisor.lock.Lock()
+	servers := append([]*ServerProcess{}, a.Supervisor.Servers...)
+	a.Supervisor.lock.Unlock()
+
+	supervised := map[string]bool{}
+	for _, server := range servers {
+		id := strings.ToLower(server.Task.Id)
+		supervised[id] = true
+		task, found := next.GetTaskById(id)
+
+		switch {
+		case !found || !serverIds[id]:
+			result.stopped = append(result.stopped, server)
+		case changed[id] && server.IsActive():
+			result.restarted = append(result.restarted, task)
+		case changed[id]:
+			// the server is not running, so it uses its new definition when it is next started
+			server.lock.Lock()
+			server.Task = task
+			server.lock.Unlock()
+		}
+	}
+
+	for _, ref := range next.Servers {
+		if task, found := next.GetTaskById(ref.TaskId()); found && !supervised[strings.ToLower(task.Id)] {
+			result.started = append(result.started, task)
+		}
+	}
+
+	result.unscheduled, result.scheduled = a.compareSchedules(next.Scheduler)
+
+	return result
+}
+
+// compareSchedules returns the cron entries that are no longer in `scheduler`, and the scheduled tasks in
+// `scheduler` that do not have a cron entry.
+func (a *Application) compareSchedules(scheduler []*ScheduledTask) ([]cron.EntryID, []*ScheduledTask) {
+	key := func(def *ScheduledTask) string {
+		return strings.ToLower(def.TaskId()) + " " + strings.TrimSpace(def.Cron)
+	}
+
+	wanted := map[string]*ScheduledTask{}
+	for _, def := range scheduler {
+		wanted[key(def)] = def
+	}
+
+	a.scheduleLock.Lock()
+	defer a.scheduleLock.Unlock()
+
+	removed := []cron.EntryID{}
+	existing := map[string]bool{}
+
+	for id, def := range a.scheduledTasks {
+		if _, found := wanted[key(def)]; found {
+			existing[key(def)] = true
+			continue
+		}
+
+		removed = append(removed, id)
+	}
+
+	added := []*ScheduledTask{}
+	for _, def := range scheduler {
+		if !existing[key(def)] {
+			existing[key(def)] = true
+			added = append(added, def)
+		}
+	}
+
+	return removed, added
+}
+
+// findServers returns the supervised server processes of `tasks`.
+func (a *Application) findServers(tasks []*Task) []*ServerProcess {
+	result := []*ServerProcess{}
+
+	for _, task := range tasks {
+		if server, found := a.Supervisor.FindServer(task.Id); found {
+			result = append(result, server)
+		}
+	}
+
+	return result
+}
+
+// displayFilenames returns `filenames` relative to the configuration file's directory where possible.
+func (a *Application) displayFilenames(filenames []string) []string {
+	result := []string{}
+	dir, _ := filepath.Abs(filepath.Dir(a.ConfigFilename))
+
+	for _, filename := range filenames {
+		if relative, err := filepath.Rel(dir, filename); err == nil && !strings.HasPrefix(relative, "..") {
+			filename = relative
+		}
+
+		result = append(result, filename)
+	}
+
+	return result
+}
+
+// sameWatchers returns true if `a` and `b` define the same watchers, in the same order.
+func sameWatchers(a []*WatchedTask, b []*WatchedTask) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !sameDefinition(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameDefinition returns true if the fields of `a` and `b` that are set by the configuration file are the
+// same.  Both must be pointers to the same type of struct.
+func sameDefinition(a any, b any) bool {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+
+	for i := 0; i < va.NumField(); i++ {
+		if tag := va.Type().Field(i).Tag.Get("yaml"); tag == "" || tag == "-" {
+			continue
+		}
+
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			return false
+		}
+	}
+
+	return true
+}
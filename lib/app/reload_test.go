@@ -0,0 +1,127 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, dir string, filename string, contents string) string {
+	path := filepath.Join(dir, filename)
+	assert.NoError(t, os.WriteFile(path, []byte(strings.ReplaceAll(contents, "$DIR", dir)), 0644))
+
+	return path
+}
+
+func serverStates(a *app.Application) map[string]app.ServerState {
+	result := map[string]app.ServerState{}
+
+	for _, server := range a.GetStatus().Servers {
+		result[server.Id] = server.State
+	}
+
+	return result
+}
+
+func TestReloadWorkflowAppliesChanges(t *testing.T) {
+	requirePosixShell(t)
+
+	dir := t.TempDir()
+
+	writeConfig(t, dir, "api.yaml", `
+name: api
+tasks:
+  - id: api
+    command: sh -c 'echo api; exec sleep 30'
+    path: $DIR
+    stop-timeout: 1s
+`)
+	config := writeConfig(t, dir, "stackup.yaml", `
+name: reload test
+includes:
+  - file: $DIR/api.yaml
+tasks:
+  - id: web
+    command: sh -c 'echo version 1; exec sleep 30'
+    path: $DIR
+    stop-timeout: 1s
+  - id: hello
+    command: echo hello
+    path: $DIR
+servers:
+  - task: web
+  - task: api
+scheduler:
+  - task: hello
+    cron: '*/5 * * * *'
+`)
+
+	a := app.NewApplication()
+	a.ConfigFilename = config
+	a.Workflow = app.CreateWorkflow(nil, &sync.Map{})
+	defer a.Supervisor.StopAll(nil, nil)
+
+	// the first reload starts every server, since none are running
+	assert.NoError(t, a.ReloadWorkflow())
+	assert.Equal(t, map[string]app.ServerState{"web": app.ServerRunning, "api": app.ServerRunning}, serverStates(a))
+
+	web, _ := a.Supervisor.FindServer("web")
+	api, _ := a.Supervisor.FindServer("api")
+	hello, _ := a.Workflow.GetTaskById("hello")
+
+	writeConfig(t, dir, "stackup.yaml", `
+name: reload test
+includes:
+  - file: $DIR/api.yaml
+tasks:
+  - id: web
+    command: sh -c 'echo version 2; exec sleep 30'
+    path: $DIR
+    stop-timeout: 1s
+  - id: hello
+    command: echo hello
+    path: $DIR
+servers:
+  - task: web
+scheduler:
+  - task: hello
+    cron: '*/10 * * * *'
+`)
+
+	assert.NoError(t, a.ReloadWorkflow())
+
+	// the changed server is restarted, and the server removed from `servers` is stopped
+	restarted, _ := a.Supervisor.FindServer("web")
+	assert.NotSame(t, web, restarted)
+	assert.Equal(t, app.ServerStopped, api.GetState())
+	assert.Equal(t, map[string]app.ServerState{"web": app.ServerRunning}, serverStates(a))
+
+	var logs struct{ Lines []string }
+	assert.Eventually(t, func() bool {
+		controlRequest(t, a, "GET", "/tasks/web/logs?lines=1", &logs)
+		return len(logs.Lines) == 1 && logs.Lines[0] == "version 2"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// tasks that did not change are kept
+	unchanged, _ := a.Workflow.GetTaskById("hello")
+	assert.Same(t, hello, unchanged)
+
+	// invalid changes are rejected without changing the running workflow
+	writeConfig(t, dir, "stackup.yaml", `
+name: reload test
+servers:
+  - task: missing
+`)
+
+	assert.Error(t, a.ReloadWorkflow())
+	assert.Equal(t, map[string]app.ServerState{"web": app.ServerRunning}, serverStates(a))
+
+	_, found := a.Workflow.GetTaskById("hello")
+	assert.True(t, found)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/schema"
+)
+
+// WorkflowSchema returns the JSON Schema for stackup configuration files.
+func WorkflowSchema() *schema.Schema {
+	return schema.Generate(reflect.TypeOf(StackupWorkflow{}), "StackUp configuration")
+}
+
+// IncludedTemplateSchema returns the JSON Schema for files that are included in a stackup configuration file.
+func IncludedTemplateSchema() *schema.Schema {
+	return schema.Generate(reflect.TypeOf(IncludedTemplate{}), "StackUp included template")
+}
+
+// writes the schema for configuration files to `w`, or the schema for included templates if `kind` is "include".
+func writeSchema(w io.Writer, kind string) error {
+	var result *schema.Schema
+
+	switch kind {
+	case "", "config":
+		result = WorkflowSchema()
+	case "include":
+		result = IncludedTemplateSchema()
+	default:
+		return errors.New(messages.SchemaCommandUsage())
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(result)
+}
@@ -0,0 +1,32 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stackup-app/stackup/lib/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowSchema(t *testing.T) {
+	s := app.WorkflowSchema()
+
+	assert.Empty(t, s.Ref)
+	assert.Equal(t, []*schema.Schema{{Ref: "#/definitions/StackupWorkflow"}}, s.AllOf)
+	assert.Equal(t, "#/definitions/Task", s.Definitions["StackupWorkflow"].Properties["tasks"].Items.Ref)
+	assert.Equal(t, "#/definitions/Settings", s.Definitions["StackupWorkflow"].Properties["settings"].Ref)
+	assert.Equal(t, "integer", s.Definitions["Task"].Properties["maxRuns"].Type)
+	assert.Equal(t, "#/definitions/ReadyProbe", s.Definitions["Task"].Properties["ready"].Ref)
+	assert.Contains(t, s.Definitions["GatewayContentTypes"].Properties, "allowed")
+	assert.NotContains(t, s.Definitions["Task"].Properties, "IncludedFrom")
+	assert.NotContains(t, s.Definitions["StackupWorkflow"].Properties, "State")
+}
+
+func TestIncludedTemplateSchema(t *testing.T) {
+	s := app.IncludedTemplateSchema()
+
+	assert.Empty(t, s.Ref)
+	assert.Equal(t, []*schema.Schema{{Ref: "#/definitions/IncludedTemplate"}}, s.AllOf)
+	assert.Contains(t, s.Definitions["IncludedTemplate"].Properties, "last-modified")
+	assert.NotContains(t, s.Definitions["IncludedTemplate"].Properties, "scheduler")
+}
@@ -0,0 +1,479 @@
+package app
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/consts"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+	"github.com/stackup-app/stackup/lib/types"
+	"github.com/stackup-app/stackup/lib/utils"
+)
+
+const (
+	RestartNever     = "never"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+type ServerState string
+
+const (
+	ServerRunning    ServerState = "running"
+	ServerRestarting ServerState = "restarting"
+	ServerExited     ServerState = "exited"
+	ServerFailed     ServerState = "failed"
+	ServerStopped    ServerState = "stopped"
+)
+
+// ServerProcess is a server task that has been started, along with the command line and options used
+// to start it so that it can be restarted without evaluating the task's scripts again.
+type ServerProcess struct {
+	Task       *Task
+	Cmd        *exec.Cmd
+	State      ServerState
+	Restarts   int
+	StartedAt  time.Time
+	ExitCode   int
+	command    string
+	opts       utils.CommandOptions
+	logMatcher *logMatcher
+	output     *taskOutput
+	exited     chan struct{}
+	// set when the server is stopped or restarted on request, rather than by its restart policy
+	stopRequested    bool
+	restartRequested bool
+	lock             sync.Mutex
+}
+
+// Supervisor waits on each started server process and restarts it according to its task's restart policy.
+type Supervisor struct {
+	Servers  []*ServerProcess
+	stopping bool
+	lock     sync.Mutex
+}
+
+func NewSupervisor() *Supervisor {
+	return &Supervisor{Servers: []*ServerProcess{}}
+}
+
+func newServerProcess(task *Task, command string, opts utils.CommandOptions) *ServerProcess {
+	result := &ServerProcess{Task: task, command: command, opts: opts}
+
+	if task.Ready == nil || task.Ready.Log == "" {
+		return result
+	}
+
+	matcher, err := newLogMatcher(task.Ready.Log)
+	if err != nil {
+		support.WarningMessage(messages.TaskInvalidReadyPattern(task.GetDisplayName(), task.Ready.Log))
+		return result
+	}
+
+	result.logMatcher = matcher
+
+	if result.opts.Silent {
+		result.opts.Silent = false
+		result.opts.Stdout, result.opts.Stderr = matcher, matcher
+	} else {
+		result.opts.Stdout = io.MultiWriter(result.opts.Stdout, matcher)
+		result.opts.Stderr = io.MultiWriter(result.opts.Stderr, matcher)
+	}
+
+	return result
+}
+
+// start creates and starts a new process for the server's command.
+func (sp *ServerProcess) start() error {
+	cmd, err := utils.NewCommand(sp.command, sp.opts)
+	if err != nil {
+		return err
+	}
+
+	if sp.Task.CommandStartCb != nil {
+		sp.Task.CommandStartCb(cmd)
+	}
+
+	if sp.logMatcher != nil {
+		sp.logMatcher.reset()
+	}
+
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+
+	sp.lock.Lock()
+	sp.Cmd = cmd
+	sp.exited = make(chan struct{})
+	sp.State = ServerRunning
+	sp.StartedAt = time.Now()
+	sp.lock.Unlock()
+
+	sp.Task.StoreProcess(sp.Task.Uuid, cmd)
+
+	event := sp.Task.newEvent(events.ServerStarted, "")
+	event.Pid = cmd.Process.Pid
+	events.Emit(event)
+
+	return nil
+}
+
+func (sp *ServerProcess) setState(state ServerState) {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	sp.State = state
+}
+
+// GetState returns the current state of the server process.
+func (sp *ServerProcess) GetState() ServerState {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	return sp.State
+}
+
+// shouldRestart returns true if the task's restart policy allows the server to be restarted after
+// exiting with `exitCode`.
+func (sp *ServerProcess) shouldRestart(exitCode int) bool {
+	sp.lock.Lock()
+	restarts := sp.Restarts
+	sp.lock.Unlock()
+
+	if restarts >= sp.Task.getMaxRestarts() {
+		return false
+	}
+
+	switch sp.Task.getRestartPolicy() {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return exitCode != 0
+	}
+
+	return false
+}
+
+// Supervise waits for `server` to exit in the background, restarting it if its restart policy allows.  A
+// server whose task is started again replaces the process that previously ran the task.
+func (s *Supervisor) Supervise(server *ServerProcess) {
+	s.lock.Lock()
+	replaced := false
+	for i, existing := range s.Servers {
+		if strings.EqualFold(existing.Task.Id, server.Task.Id) {
+			s.Servers[i], replaced = server, true
+		}
+	}
+	if !replaced {
+		s.Servers = append(s.Servers, server)
+	}
+	s.lock.Unlock()
+
+	go s.watch(server)
+}
+
+// Stop marks the supervisor as stopping, so that servers exiting from now on are not restarted.
+func (s *Supervisor) Stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stopping = true
+}
+
+// StopAll stops each running server in the reverse of the order they were started.  Each server is sent
+// its stop signal and is killed if it has not exited once its stop timeout has elapsed.
+func (s *Supervisor) StopAll(signal types.SignalCommandCallback, kill types.CommandCallback) {
+	s.Stop()
+
+	s.lock.Lock()
+	servers := utils.ReverseArray(append([]*ServerProcess{}, s.Servers...))
+	s.lock.Unlock()
+
+	for _, server := range servers {
+		server.stop(signal, kill)
+	}
+}
+
+func (sp *ServerProcess) stop(signal types.SignalCommandCallback, kill types.CommandCallback) {
+	sp.lock.Lock()
+	cmd, exited, state := sp.Cmd, sp.exited, sp.State
+	sp.lock.Unlock()
+
+	if state != ServerRunning {
+		return
+	}
+
+	task := sp.Task
+	timeout := task.getStopTimeout()
+
+	support.StatusMessageLine(messages.ServerStopping(task.GetDisplayName()), false)
+
+	// windows can only kill a process, so there is no stop signal to wait on
+	if runtime.GOOS == "windows" {
+		killCommand(cmd, kill)
+		<-exited
+		support.SuccessMessageWithCheck(messages.ServerStopped(task.GetDisplayName(), cmd.ProcessState.String()))
+		return
+	}
+
+	if signal != nil {
+		signal(cmd, task.getStopSignal())
+	} else {
+		cmd.Process.Signal(task.getStopSignal())
+	}
+
+	select {
+	case <-exited:
+		support.SuccessMessageWithCheck(messages.ServerStopped(task.GetDisplayName(), cmd.ProcessState.String()))
+		return
+	case <-time.After(timeout):
+	}
+
+	killCommand(cmd, kill)
+	<-exited
+
+	support.FailureMessageWithXMark(messages.ServerKilled(task.GetDisplayName(), timeout))
+}
+
+func killCommand(cmd *exec.Cmd, kill types.CommandCallback) {
+	if kill != nil {
+		kill(cmd)
+	} else {
+		cmd.Process.Kill()
+	}
+}
+
+// FindServer returns the most recently started server process for the task with the id `id`.
+func (s *Supervisor) FindServer(id string) (*ServerProcess, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := len(s.Servers) - 1; i >= 0; i-- {
+		if strings.EqualFold(s.Servers[i].Task.Id, id) {
+			return s.Servers[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Remove stops supervising `server`, which should already have been stopped.
+func (s *Supervisor) Remove(server *ServerProcess) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, existing := range s.Servers {
+		if existing == server {
+			s.Servers = append(s.Servers[:i], s.Servers[i+1:]...)
+			return
+		}
+	}
+}
+
+// IsActive returns true if the server is running, or is waiting to be restarted by its restart policy.
+func (sp *ServerProcess) IsActive() bool {
+	state := sp.GetState()
+
+	return state == ServerRunning || state == ServerRestarting
+}
+
+// StopServer stops a single server without restarting it, regardless of its restart policy.
+func (s *Supervisor) StopServer(server *ServerProcess, signal types.SignalCommandCallback, kill types.CommandCallback) {
+	server.lock.Lock()
+	server.stopRequested = true
+	server.restartRequested = false
+	server.lock.Unlock()
+
+	server.stop(signal, kill)
+}
+
+// RestartServer stops a running server and starts it again immediately, without counting the restart
+// against its `max-restarts` setting.  Returns false if the server was not running.
+func (s *Supervisor) RestartServer(server *ServerProcess, signal types.SignalCommandCallback, kill types.CommandCallback) bool {
+	server.lock.Lock()
+	running := server.State == ServerRunning
+	server.restartRequested = running
+	server.lock.Unlock()
+
+	if running {
+		server.stop(signal, kill)
+	}
+
+	return running
+}
+
+func (sp *ServerProcess) isStopRequested() bool {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	return sp.stopRequested
+}
+
+// takeRestartRequest returns true if a restart was requested, clearing the request.
+func (sp *ServerProcess) takeRestartRequest() bool {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	result := sp.restartRequested
+	sp.restartRequested = false
+
+	return result
+}
+
+func (s *Supervisor) isStopping() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.stopping
+}
+
+func (s *Supervisor) watch(server *ServerProcess) {
+	task := server.Task
+
+	if server.output != nil {
+		defer server.output.Close()
+	}
+
+	for {
+		server.lock.Lock()
+		cmd := server.Cmd
+		server.lock.Unlock()
+
+		cmd.Wait()
+
+		if server.output != nil {
+			server.output.Flush()
+		}
+
+		server.lock.Lock()
+		server.ExitCode = cmd.ProcessState.ExitCode()
+		exitCode := server.ExitCode
+		status := cmd.ProcessState.String()
+		close(server.exited)
+		server.lock.Unlock()
+
+		task.Workflow.ProcessMap.Delete(task.Uuid)
+
+		if s.isStopping() || server.isStopRequested() {
+			server.setState(ServerStopped)
+			events.Emit(task.newEvent(events.ServerStopped, status).WithExitCode(exitCode))
+			return
+		}
+
+		if server.takeRestartRequest() {
+			support.StatusMessageLine(messages.ServerRestartRequested(task.GetDisplayName()), false)
+			events.Emit(task.newEvent(events.ServerRestarting, status).WithExitCode(exitCode))
+
+			if err := server.start(); err != nil {
+				support.FailureMessageWithXMark(task.GetDisplayName() + ": " + err.Error())
+				server.setState(ServerFailed)
+				return
+			}
+
+			continue
+		}
+
+		events.Emit(task.newEvent(events.ServerExited, status).WithExitCode(exitCode))
+
+		if exitCode == 0 {
+			support.WarningMessage(messages.ServerExited(task.GetDisplayName(), status))
+		} else {
+			support.FailureMessageWithXMark(messages.ServerExited(task.GetDisplayName(), status))
+		}
+
+		if !server.shouldRestart(exitCode) {
+			if exitCode == 0 {
+				server.setState(ServerExited)
+			} else {
+				server.setState(ServerFailed)
+			}
+			return
+		}
+
+		server.lock.Lock()
+		server.Restarts++
+		restarts := server.Restarts
+		server.State = ServerRestarting
+		server.lock.Unlock()
+
+		delay := task.getRestartDelay(restarts)
+		message := messages.ServerRestarting(task.GetDisplayName(), delay, restarts, task.getMaxRestarts())
+		support.StatusMessageLine(message, false)
+
+		event := task.newEvent(events.ServerRestarting, message)
+		event.Attempt = restarts
+		events.Emit(event)
+		time.Sleep(delay)
+
+		if s.isStopping() || server.isStopRequested() {
+			server.setState(ServerStopped)
+			events.Emit(task.newEvent(events.ServerStopped, ""))
+			return
+		}
+
+		if err := server.start(); err != nil {
+			support.FailureMessageWithXMark(task.GetDisplayName() + ": " + err.Error())
+			server.setState(ServerFailed)
+			return
+		}
+	}
+}
+
+// returns the task's restart policy, which is "never" if the task does not have a valid policy.
+func (task *Task) getRestartPolicy() string {
+	policy := strings.ToLower(strings.TrimSpace(task.Restart))
+
+	switch policy {
+	case RestartAlways, RestartOnFailure, RestartNever:
+		return policy
+	case "":
+		return RestartNever
+	}
+
+	support.WarningMessage(messages.TaskInvalidRestartPolicy(task.GetDisplayName(), task.Restart))
+
+	return RestartNever
+}
+
+func (task *Task) getMaxRestarts() int {
+	if task.MaxRestarts <= 0 {
+		return consts.DEFAULT_MAX_RESTARTS
+	}
+
+	return task.MaxRestarts
+}
+
+// returns the signal sent to the task's process group to stop it, which is SIGTERM by default.
+func (task *Task) getStopSignal() os.Signal {
+	if task.StopSignal == "" {
+		return syscall.SIGTERM
+	}
+
+	result, found := utils.ParseSignal(task.StopSignal)
+	if !found {
+		support.WarningMessage(messages.TaskInvalidStopSignal(task.GetDisplayName(), task.StopSignal))
+		return syscall.SIGTERM
+	}
+
+	return result
+}
+
+// returns how long to wait for the task's process to exit after sending the stop signal before killing it.
+func (task *Task) getStopTimeout() time.Duration {
+	return task.parseDuration("stop-timeout", task.StopTimeout, consts.DEFAULT_STOP_TIMEOUT_SECONDS*time.Second)
+}
+
+// returns the delay before restart number `restart`, starting at 1.  The delay doubles after each restart.
+func (task *Task) getRestartDelay(restart int) time.Duration {
+	delay := task.parseDuration("restart-delay", task.RestartDelay, consts.DEFAULT_RETRY_DELAY_SECONDS*time.Second)
+
+	return utils.BackoffDelay(delay, restart, true, consts.MAX_RETRY_DELAY_SECONDS*time.Second)
+}
@@ -0,0 +1,66 @@
+package app_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+// requirePosixShell skips tests whose commands are run by a posix shell.
+func requirePosixShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+}
+
+func superviseTask(t *testing.T, task *app.Task) (*app.ServerProcess, *app.Supervisor) {
+	requirePosixShell(t)
+
+	workflow := app.CreateWorkflow(nil, &sync.Map{})
+	workflow.Tasks = []*app.Task{task}
+	task.Initialize(workflow)
+
+	server := task.RunAsync()
+	assert.NotNil(t, server)
+
+	supervisor := app.NewSupervisor()
+	supervisor.Supervise(server)
+
+	return server, supervisor
+}
+
+func TestSupervisorRestartsFailedServers(t *testing.T) {
+	server, _ := superviseTask(t, &app.Task{Id: "server", Command: "sh -c 'exit 3'", Path: ".", Restart: "on-failure", MaxRestarts: 2, RestartDelay: "1ms"})
+
+	assert.Eventually(t, func() bool { return server.GetState() == app.ServerFailed }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, server.Restarts)
+	assert.Equal(t, 3, server.ExitCode)
+}
+
+func TestSupervisorDoesNotRestartWithNeverPolicy(t *testing.T) {
+	server, _ := superviseTask(t, &app.Task{Id: "server", Command: "true", Path: ".", RestartDelay: "1ms"})
+
+	assert.Eventually(t, func() bool { return server.GetState() == app.ServerExited }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, server.Restarts)
+}
+
+func TestSupervisorStopAllSendsStopSignal(t *testing.T) {
+	server, supervisor := superviseTask(t, &app.Task{Id: "server", Command: "sleep 5", Path: ".", StopTimeout: "5s"})
+	start := time.Now()
+	supervisor.StopAll(nil, nil)
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Eventually(t, func() bool { return server.GetState() == app.ServerStopped }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSupervisorStopAllKillsAfterStopTimeout(t *testing.T) {
+	server, supervisor := superviseTask(t, &app.Task{Id: "server", Command: `sh -c 'trap "" TERM; exec sleep 5'`, Path: ".", StopTimeout: "100ms"})
+	time.Sleep(50 * time.Millisecond)
+	supervisor.StopAll(nil, nil)
+
+	assert.Equal(t, -1, server.ExitCode)
+}
@@ -1,10 +1,20 @@
 package app
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/joho/godotenv"
 	"github.com/stackup-app/stackup/lib/consts"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
 	"github.com/stackup-app/stackup/lib/scripting"
 	"github.com/stackup-app/stackup/lib/settings"
 	"github.com/stackup-app/stackup/lib/support"
@@ -13,23 +23,46 @@ import (
 )
 
 type Task struct {
-	Name           string   `yaml:"name"`
-	Command        string   `yaml:"command"`
-	If             string   `yaml:"if,omitempty"`
-	Id             string   `yaml:"id,omitempty"`
-	Silent         bool     `yaml:"silent"`
-	Path           string   `yaml:"path"`
-	Platforms      []string `yaml:"platforms,omitempty"`
-	MaxRuns        int      `yaml:"maxRuns,omitempty"`
-	Include        string   `yaml:"include,omitempty"`
+	Name           string      `yaml:"name"`
+	Command        string      `yaml:"command"`
+	If             string      `yaml:"if,omitempty"`
+	Id             string      `yaml:"id,omitempty"`
+	Silent         bool        `yaml:"silent"`
+	Path           string      `yaml:"path"`
+	Shell          string      `yaml:"shell,omitempty"`
+	Platforms      []string    `yaml:"platforms,omitempty"`
+	MaxRuns        int         `yaml:"maxRuns,omitempty"`
+	Include        string      `yaml:"include,omitempty"`
+	DependsOn      []string    `yaml:"depends-on,omitempty"`
+	Env            []string    `yaml:"env,omitempty"`
+	EnvFile        string      `yaml:"env-file,omitempty"`
+	Timeout        string      `yaml:"timeout,omitempty"`
+	Retries        int         `yaml:"retries,omitempty"`
+	RetryDelay     string      `yaml:"retry-delay,omitempty"`
+	RetryBackoff   string      `yaml:"retry-backoff,omitempty"`
+	Restart        string      `yaml:"restart,omitempty"`
+	MaxRestarts    int         `yaml:"max-restarts,omitempty"`
+	RestartDelay   string      `yaml:"restart-delay,omitempty"`
+	Ready          *ReadyProbe `yaml:"ready,omitempty"`
+	StopSignal     string      `yaml:"stop-signal,omitempty"`
+	StopTimeout    string      `yaml:"stop-timeout,omitempty"`
+	Output         string      `yaml:"output,omitempty"`
+	LogFile        string      `yaml:"log-file,omitempty"`
+	Hooks          *TaskHooks  `yaml:"hooks,omitempty"`
 	RunCount       int
 	Uuid           string
 	FromRemote     bool
+	IncludedFrom   string
 	CommandStartCb types.CommandCallback
-	//Workflow   *StackupWorkflow //*types.AppWorkflowContract
-	JsEngine     *scripting.JavaScriptEngine
-	setActive    SetActiveTaskCallback
-	StoreProcess types.SetProcessCallback
+	KillCommandCb  types.CommandCallback
+	Workflow       *StackupWorkflow
+	JsEngine       *scripting.JavaScriptEngine
+	setActive      SetActiveTaskCallback
+	StoreProcess   types.SetProcessCallback
+	environment    []string
+	exitCode       int
+	logs           *logBuffer
+	runningHooks   atomic.Bool
 	// types.AppWorkflowTaskContract
 }
 
@@ -39,7 +72,8 @@ type TaskReferenceContract interface {
 }
 
 type TaskReference struct {
-	Task     string `yaml:"task"`
+	Task     string           `yaml:"task"`
+	Parallel []*TaskReference `yaml:"parallel,omitempty"`
 	Workflow *StackupWorkflow
 	JsEngine *scripting.JavaScriptEngine
 	TaskReferenceContract
@@ -53,6 +87,18 @@ type ScheduledTask struct {
 	TaskReferenceContract
 }
 
+// WatchedTask runs a task when the files matching its paths change.
+type WatchedTask struct {
+	Task     string   `yaml:"task"`
+	Paths    []string `yaml:"paths"`
+	Ignore   []string `yaml:"ignore,omitempty"`
+	Debounce string   `yaml:"debounce,omitempty"`
+	Interval string   `yaml:"interval,omitempty"`
+	Workflow *StackupWorkflow
+	JsEngine *scripting.JavaScriptEngine
+	TaskReferenceContract
+}
+
 func (task *Task) canRunOnCurrentPlatform() bool {
 	if task.Platforms == nil || len(task.Platforms) == 0 {
 		return true
@@ -76,6 +122,7 @@ func (task *Task) canRunConditionally() bool {
 }
 
 func (task *Task) Initialize(workflow *StackupWorkflow) { //} *scripting.JavaScriptEngine, cmdStartCb types.CommandCallback, setActive SetActiveTaskCallback, storeProcess types.SetProcessCallback) {
+	task.Workflow = workflow
 	task.JsEngine = workflow.JsEngine
 	if workflow.State.CurrentTask != nil {
 		task.setActive = workflow.State.CurrentTask.setActive
@@ -83,11 +130,13 @@ func (task *Task) Initialize(workflow *StackupWorkflow) { //} *scripting.JavaScr
 		task.setActive = func(task *Task) CleanupCallback { return func() {} }
 	}
 	task.CommandStartCb = workflow.CommandStartCb
+	task.KillCommandCb = workflow.KillCommandCb
 	task.StoreProcess = workflow.ProcessMap.Store
 	task.Uuid = utils.GenerateTaskUuid()
 
 	task.RunCount = 0
 	task.MaxRuns = utils.Max(task.MaxRuns, 0)
+	task.logs = newLogBuffer(consts.MAX_TASK_LOG_LINES)
 
 	if task.MaxRuns == 0 {
 		task.MaxRuns = consts.MAX_TASK_RUNS
@@ -102,6 +151,15 @@ func (task *Task) Initialize(workflow *StackupWorkflow) { //} *scripting.JavaScr
 	task.setDefaultSettings(workflow.Settings)
 }
 
+// inheritState keeps the run count, exit code and output of `previous`, the task's definition before the
+// workflow was reloaded.
+func (task *Task) inheritState(previous *Task) {
+	task.Uuid = previous.Uuid
+	task.RunCount = previous.RunCount
+	task.exitCode = previous.exitCode
+	task.logs = previous.logs
+}
+
 func (task *Task) setDefaultSettings(s *settings.Settings) {
 	task.Silent = s.Defaults.Tasks.Silent
 
@@ -109,12 +167,19 @@ func (task *Task) setDefaultSettings(s *settings.Settings) {
 		task.Path = utils.FirstNonEmpty(s.Defaults.Tasks.Path, consts.DEFAULT_CWD_SETTING)
 	}
 
+	if task.Shell == "" {
+		task.Shell = s.Defaults.Tasks.Shell
+	}
+
+	utils.SetIfEmpty(&task.StopSignal, s.Defaults.Tasks.StopSignal)
+	utils.SetIfEmpty(&task.StopTimeout, s.Defaults.Tasks.StopTimeout)
+
 	if len(task.Platforms) == 0 {
 		copy(task.Platforms, s.Defaults.Tasks.Platforms)
 	}
 }
 
-func (task Task) GetDisplayName() string {
+func (task *Task) GetDisplayName() string {
 	if len(task.Include) > 0 {
 		return strings.TrimPrefix(task.Include, "https://")
 	}
@@ -140,20 +205,73 @@ func (task *Task) getCommand() string {
 	return result
 }
 
-func (task *Task) prepareRun() (bool, func()) {
-	if task.Uuid == "" {
-		task.Uuid = utils.GenerateTaskUuid()
+func (task *Task) commandOptions(output *taskOutput) utils.CommandOptions {
+	result := utils.CommandOptions{
+		Cwd:   task.Path,
+		Shell: task.Shell,
+		Env:   task.environment,
 	}
 
-	result := task.setActive(task)
+	output.apply(&result)
 
-	if task.RunCount >= task.MaxRuns && task.MaxRuns > 0 {
-		support.SkippedMessageWithSymbol(task.GetDisplayName())
-		return false, nil
+	return result
+}
+
+// getEnvironment returns the variables loaded from the task's `env-file` followed by the task's `env`
+// entries, as `KEY=value` strings.  `{{ }}` expressions in `env` values are evaluated, and `$VAR`
+// references are expanded using the `env-file` variables and the process environment.
+func (task *Task) getEnvironment() []string {
+	result := []string{}
+	fileVars := map[string]string{}
+
+	if task.EnvFile != "" {
+		filename := os.ExpandEnv(task.EnvFile)
+		if task.JsEngine.IsEvaluatableScriptString(filename) {
+			filename = task.JsEngine.Evaluate(filename).(string)
+		}
+
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(task.Path, filename)
+		}
+
+		vars, err := godotenv.Read(filename)
+		if err != nil {
+			support.WarningMessage(messages.TaskEnvFileNotLoaded(task.GetDisplayName(), filename))
+		}
+
+		for name, value := range vars {
+			fileVars[name] = value
+			result = append(result, name+"="+value)
+		}
+
+		sort.Strings(result)
 	}
 
-	task.RunCount++
+	defs := []string{}
+	for _, def := range task.Env {
+		name, value, found := strings.Cut(def, "=")
+		if !found {
+			continue
+		}
+
+		if task.JsEngine.IsEvaluatableScriptString(value) {
+			value = fmt.Sprintf("%v", task.JsEngine.Evaluate(value))
+		}
+
+		defs = append(defs, name+"="+value)
+	}
+
+	return append(result, utils.ExpandEnvDefs(defs, func(name string) (string, bool) {
+		if value, found := fileVars[name]; found {
+			return value, true
+		}
+
+		return os.LookupEnv(name)
+	})...)
+}
 
+// resolvePath evaluates the task's path if it is a script or an environment variable reference.
+func (task *Task) resolvePath() {
 	// allow the path property to be an environment variable reference without wrapping it in `{{ }}`
 	if utils.MatchesPattern(task.Path, "^\\$[\\w_]+$") {
 		task.Path = task.JsEngine.MakeStringEvaluatable(task.Path)
@@ -162,86 +280,280 @@ func (task *Task) prepareRun() (bool, func()) {
 	if task.JsEngine.IsEvaluatableScriptString(task.Path) {
 		task.Path = task.JsEngine.Evaluate(task.Path).(string)
 	}
+}
+
+// beginRun determines if the task can run, evaluating its path and conditions.  If the task cannot run,
+// the message describing why it was skipped is returned.
+func (task *Task) beginRun() (bool, string, func()) {
+	if task.Uuid == "" {
+		task.Uuid = utils.GenerateTaskUuid()
+	}
+
+	result := task.setActive(task)
+
+	if task.RunCount >= task.MaxRuns && task.MaxRuns > 0 {
+		return false, task.GetDisplayName(), nil
+	}
+
+	task.RunCount++
+	task.resolvePath()
 
 	if !task.canRunConditionally() {
-		support.SkippedMessageWithSymbol(task.GetDisplayName())
-		return false, nil
+		return false, task.GetDisplayName(), nil
 	}
 
 	if !task.canRunOnCurrentPlatform() {
-		support.SkippedMessageWithSymbol("Task '" + task.GetDisplayName() + "' is not supported on this operating system.")
-		return false, nil
+		return false, "Task '" + task.GetDisplayName() + "' is not supported on this operating system.", nil
+	}
+
+	task.environment = task.getEnvironment()
+
+	return true, "", result
+}
+
+// newEvent returns an event of type `eventType` for the task.
+func (task *Task) newEvent(eventType string, message string) events.Event {
+	return events.Event{Type: eventType, Task: task.Id, Name: task.GetDisplayName(), Message: message}
+}
+
+func (task *Task) emitRetrying(attempt int, message string) {
+	event := task.newEvent(events.TaskRetrying, message)
+	event.Attempt = attempt
+
+	events.Emit(event)
+}
+
+// emitFinished emits the event for a run of the task that started at `startedAt` and completed with `err`.
+func (task *Task) emitFinished(err error, startedAt time.Time) {
+	event := task.newEvent(events.TaskFinished, "").WithExitCode(utils.ExitCode(err)).WithDuration(time.Since(startedAt))
+
+	switch {
+	case err == nil:
+		event.Status = "success"
+	case errors.Is(err, utils.ErrCommandTimedOut):
+		event.Status = "timeout"
+	default:
+		event.Status = "failed"
+	}
+
+	events.Emit(event)
+}
+
+// runStatus is the result of a run of a task.
+type runStatus int
+
+const (
+	runSucceeded runStatus = iota
+	runFailed
+	// the task was not run because of its platforms, its `if` condition or its maximum number of runs
+	runSkipped
+)
+
+// prepareRun starts a run of the task and runs its `before` hook.  It returns runSucceeded and a cleanup
+// function if the task's command can be run.
+func (task *Task) prepareRun() (runStatus, func()) {
+	canRun, skipped, cleanup := task.beginRun()
+
+	if !canRun {
+		support.SkippedMessageWithSymbol(skipped)
+		events.Emit(task.newEvent(events.TaskSkipped, skipped))
+		return runSkipped, nil
+	}
+
+	if !task.runBeforeHook() {
+		task.skipAfterFailedHook()
+		cleanup()
+		return runFailed, nil
 	}
 
 	support.StatusMessage(task.GetDisplayName()+"...", false)
 
-	return true, result
+	return runSucceeded, cleanup
 }
 
-func (task *Task) RunSync() bool {
-	var canRun bool
-	var cleanup func()
+// skipAfterFailedHook reports that the task did not run because its `before` hook failed.
+func (task *Task) skipAfterFailedHook() {
+	message := messages.TaskHookFailed(task.GetDisplayName(), "before")
+
+	task.exitCode = 1
+	support.FailureMessageWithXMark(message)
+	events.Emit(task.newEvent(events.TaskSkipped, message))
+}
 
-	if canRun, cleanup = task.prepareRun(); !canRun {
+// parses the duration `value` of the setting named `setting`, returning `defaultValue` if it is empty or invalid.
+func (task *Task) parseDuration(setting string, value string, defaultValue time.Duration) time.Duration {
+	if value == "" {
+		return defaultValue
+	}
+
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		support.WarningMessage(messages.TaskInvalidDuration(task.GetDisplayName(), setting, value))
+		return defaultValue
+	}
+
+	return result
+}
+
+// returns the task's timeout, or zero if the task does not have a valid timeout.
+func (task *Task) getTimeout() time.Duration {
+	return task.parseDuration("timeout", task.Timeout, 0)
+}
+
+// returns the delay before retry number `attempt`, starting at 1.
+func (task *Task) getRetryDelay(attempt int) time.Duration {
+	delay := task.parseDuration("retry-delay", task.RetryDelay, consts.DEFAULT_RETRY_DELAY_SECONDS*time.Second)
+	exponential := strings.EqualFold(task.RetryBackoff, "exponential")
+
+	return utils.BackoffDelay(delay, attempt, exponential, consts.MAX_RETRY_DELAY_SECONDS*time.Second)
+}
+
+// execute runs the command and waits for it to complete.  If the task has a timeout, the command is
+// started in its own process group so the entire process tree can be killed when it times out.
+func (task *Task) execute(command string, opts utils.CommandOptions) error {
+	cmd, err := utils.NewCommand(command, opts)
+	if err != nil {
+		return err
+	}
+
+	timeout := task.getTimeout()
+	if timeout > 0 && task.CommandStartCb != nil {
+		task.CommandStartCb(cmd)
+	}
+
+	return utils.RunWithTimeout(cmd, timeout, task.KillCommandCb)
+}
+
+// executeWithRetries runs the command, running it again up to `retries` times while it fails.  `onRetry`
+// is called before waiting to retry.
+func (task *Task) executeWithRetries(command string, opts utils.CommandOptions, onRetry func(attempt int, delay time.Duration)) error {
+	err := task.execute(command, opts)
+
+	for attempt := 1; err != nil && attempt <= task.Retries; attempt++ {
+		delay := task.getRetryDelay(attempt)
+		onRetry(attempt, delay)
+		time.Sleep(delay)
+
+		err = task.execute(command, opts)
+	}
+
+	return err
+}
+
+// RunSync runs the task's dependencies, then runs the task and its hooks and waits for them to complete.
+func (task *Task) RunSync() bool {
+	if !task.runDependencies() {
+		task.exitCode = 1
 		return false
 	}
 
+	return task.run() == runSucceeded
+}
+
+func (task *Task) run() runStatus {
+	status, cleanup := task.prepareRun()
+	if status != runSucceeded {
+		return status
+	}
+
 	defer cleanup()
 
-	cmd, err := utils.RunCommandInPath(task.getCommand(), task.Path, task.Silent)
+	result := task.runCommand()
+	task.runCompletionHooks(result)
+
+	if !result {
+		return runFailed
+	}
+
+	return runSucceeded
+}
+
+// runCommand runs the task's command, displaying its output and result.
+func (task *Task) runCommand() bool {
+	output := task.openOutput(task.getDefaultOutputMode(), os.Stdout, os.Stderr)
+	defer output.Close()
+
+	startedAt := time.Now()
+	events.Emit(task.newEvent(events.TaskStarted, ""))
+
+	err := task.executeWithRetries(task.getCommand(), task.commandOptions(output), func(attempt int, delay time.Duration) {
+		message := messages.TaskRetrying(task.GetDisplayName(), attempt, task.Retries, delay)
+		support.WarningMessage(message)
+		task.emitRetrying(attempt, message)
+	})
+	task.exitCode = utils.ExitCode(err)
+	if task.Workflow != nil {
+		task.Workflow.State.SetCompleted(task, err == nil)
+	}
+
+	output.Flush()
+	task.emitFinished(err, startedAt)
+
+	if errors.Is(err, utils.ErrCommandTimedOut) {
+		support.FailureMessageWithXMark(messages.TaskTimedOut(task.GetDisplayName(), task.Timeout))
+		return false
+	}
+
 	if err != nil {
 		support.FailureMessageWithXMark(task.GetDisplayName())
 		return false
 	}
 
-	if cmd != nil && task.Silent {
+	if task.Silent {
 		support.PrintCheckMarkLine()
-	} else if cmd != nil {
+	} else {
 		support.SuccessMessageWithCheck(task.GetDisplayName())
 	}
 
-	if cmd == nil && task.Silent {
-		support.PrintXMarkLine()
-	} else if cmd == nil {
-		support.FailureMessageWithXMark(task.GetDisplayName())
-	}
-
 	return true
 }
 
-func (task *Task) RunAsync() {
-	var canRun bool
-	var cleanup func()
+// ExitCode returns the exit code of the most recent run of the task's command.
+func (task *Task) ExitCode() int {
+	return task.exitCode
+}
 
-	if canRun, cleanup = task.prepareRun(); !canRun {
-		return
+// RunAsync runs the task's dependencies, then starts the task without waiting for it to complete.
+// Returns nil if the task was not started.
+func (task *Task) RunAsync() *ServerProcess {
+	if !task.runDependencies() {
+		return nil
 	}
 
-	defer cleanup()
-
-	command := task.getCommand()
-	cmd := utils.StartCommand(command, task.Path, false)
-
-	if cmd == nil {
-		support.FailureMessageWithXMark(task.GetDisplayName())
-		return
+	status, cleanup := task.prepareRun()
+	if status != runSucceeded {
+		return nil
 	}
 
-	task.CommandStartCb(cmd)
-	err := cmd.Start()
+	defer cleanup()
 
-	if err != nil {
+	output := task.openOutput(OutputPrefixed, os.Stdout, os.Stderr)
+	server := newServerProcess(task, task.getCommand(), task.commandOptions(output))
+	server.output = output
+
+	if err := server.start(); err != nil {
+		output.Close()
 		support.PrintXMarkLine()
-	} else {
-		support.PrintCheckMarkLine()
+		return nil
 	}
 
-	task.StoreProcess(task.Uuid, cmd)
+	support.PrintCheckMarkLine()
+
+	return server
 }
 
 func (tr *TaskReference) Initialize(workflow *StackupWorkflow) {
 	tr.Workflow = workflow
 	tr.JsEngine = workflow.JsEngine
+
+	for _, ref := range tr.Parallel {
+		ref.Initialize(workflow)
+	}
+}
+
+// IsParallel returns true if the reference is a group of task references that run at the same time.
+func (tr *TaskReference) IsParallel() bool {
+	return len(tr.Parallel) > 0
 }
 
 func (tr *TaskReference) TaskId() string {
@@ -268,3 +580,20 @@ func (st *ScheduledTask) Initialize(workflow *StackupWorkflow) {
 		st.Task = workflow.JsEngine.Evaluate(st.Task).(string)
 	}
 }
+
+func (wt *WatchedTask) TaskId() string {
+	if wt.JsEngine.IsEvaluatableScriptString(wt.Task) {
+		return wt.JsEngine.Evaluate(wt.Task).(string)
+	}
+
+	return wt.Task
+}
+
+func (wt *WatchedTask) Initialize(workflow *StackupWorkflow) {
+	wt.Workflow = workflow
+	wt.JsEngine = workflow.JsEngine
+
+	if workflow.JsEngine.IsEvaluatableScriptString(wt.Task) {
+		wt.Task = workflow.JsEngine.Evaluate(wt.Task).(string)
+	}
+}
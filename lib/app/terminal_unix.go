@@ -0,0 +1,19 @@
+//go:build !windows
+
+package app
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalSize returns the number of columns and rows of the terminal that stdout is written to.
+func terminalSize() (int, int, bool) {
+	size, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || size.Col == 0 || size.Row == 0 {
+		return 0, 0, false
+	}
+
+	return int(size.Col), int(size.Row), true
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package app
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// terminalSize returns the number of columns and rows of the console window that stdout is written to.
+func terminalSize() (int, int, bool) {
+	var info windows.ConsoleScreenBufferInfo
+
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(os.Stdout.Fd()), &info); err != nil {
+		return 0, 0, false
+	}
+
+	return int(info.Window.Right-info.Window.Left) + 1, int(info.Window.Bottom-info.Window.Top) + 1, true
+}
@@ -0,0 +1,454 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stackup-app/stackup/lib/consts"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/utils"
+	"github.com/stackup-app/stackup/lib/watcher"
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in a configuration file.  `Line` and `Column` are zero if the problem
+// is not specific to a location in the file.
+type ValidationError struct {
+	Filename string
+	Line     int
+	Column   int
+	Message  string
+	// warnings are reported at startup, but only cause the `validate` command to fail
+	Warning bool
+}
+
+func (e *ValidationError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.Filename, e.Message)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Line, e.Column, e.Message)
+}
+
+// configDocument is a parsed configuration file, either the main configuration file or an include.
+type configDocument struct {
+	filename string
+	root     *yaml.Node
+}
+
+// includedSource is the contents of an included file, kept so that it can be validated.
+type includedSource struct {
+	name     string
+	contents string
+}
+
+type workflowValidator struct {
+	documents []*configDocument
+	taskNodes map[string]*yaml.Node
+	taskDocs  map[string]*configDocument
+	errors    []*ValidationError
+	// task references can't be checked if some task ids are scripts that are only known at runtime, or
+	// if an include could not be loaded
+	skipTaskReferences bool
+}
+
+var yamlErrorLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+func newWorkflowValidator() *workflowValidator {
+	return &workflowValidator{
+		documents: []*configDocument{},
+		taskNodes: map[string]*yaml.Node{},
+		taskDocs:  map[string]*configDocument{},
+		errors:    []*ValidationError{},
+	}
+}
+
+func (v *workflowValidator) addError(doc *configDocument, node *yaml.Node, message string) *ValidationError {
+	err := &ValidationError{Filename: doc.filename, Message: message}
+
+	if node != nil {
+		err.Line, err.Column = node.Line, node.Column
+	}
+
+	v.errors = append(v.errors, err)
+
+	return err
+}
+
+// addErrorAtLine adds an error for a yaml parser message such as "line 3: cannot unmarshal...", using the
+// last node found on that line as the error location.
+func (v *workflowValidator) addErrorAtLine(doc *configDocument, message string) {
+	matches := yamlErrorLinePattern.FindStringSubmatch(strings.TrimSpace(message))
+	if matches == nil {
+		v.addError(doc, nil, message)
+		return
+	}
+
+	line, _ := strconv.Atoi(matches[1])
+	node := findNodeOnLine(doc.root, line)
+
+	if node == nil {
+		node = &yaml.Node{Line: line, Column: 1}
+	}
+
+	v.addError(doc, node, matches[2])
+}
+
+func findNodeOnLine(node *yaml.Node, line int) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+
+	var result *yaml.Node
+	if node.Line == line && node.Kind != yaml.DocumentNode {
+		result = node
+	}
+
+	for _, child := range node.Content {
+		if found := findNodeOnLine(child, line); found != nil {
+			result = found
+		}
+	}
+
+	return result
+}
+
+// addDocument parses `contents` and checks it for syntax errors, unknown fields, and values that cannot be
+// decoded into `target`.
+func (v *workflowValidator) addDocument(filename string, contents []byte, target reflect.Type) {
+	doc := &configDocument{filename: filename}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(contents, &root); err != nil {
+		v.addErrorAtLine(doc, err.Error())
+		return
+	}
+
+	if len(root.Content) > 0 {
+		doc.root = root.Content[0]
+	}
+
+	v.documents = append(v.documents, doc)
+	v.checkFields(doc, doc.root, target, "")
+
+	var typeErr *yamlv2.TypeError
+	if err := yamlv2.Unmarshal(contents, reflect.New(target).Interface()); errors.As(err, &typeErr) {
+		for _, message := range typeErr.Errors {
+			v.addErrorAtLine(doc, message)
+		}
+	}
+}
+
+// checkFields reports mapping keys that do not match a configuration field of `t`.  `parent` is the key
+// that contains `node`, used to describe where an unknown field was found.
+func (v *workflowValidator) checkFields(doc *configDocument, node *yaml.Node, t reflect.Type, parent string) {
+	if node == nil {
+		return
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := utils.YamlFields(t)
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			field, found := fields[key.Value]
+			if !found {
+				v.addError(doc, key, messages.ValidationUnknownField(key.Value, parent, suggestFieldName(key.Value, fields))).Warning = true
+				continue
+			}
+
+			v.checkFields(doc, value, field.Type, key.Value)
+		}
+
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			v.checkFields(doc, item, t.Elem(), parent)
+		}
+
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			v.checkFields(doc, node.Content[i], t.Elem(), parent)
+		}
+	}
+}
+
+// suggestFieldName returns the name of a field that `name` is likely a misspelling of, such as "maxRuns"
+// for "maxruns" or "depends-on" for "depends_on".
+func suggestFieldName(name string, fields map[string]reflect.StructField) string {
+	normalize := func(s string) string {
+		return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(s))
+	}
+
+	for fieldName := range fields {
+		if normalize(fieldName) == normalize(name) {
+			return fieldName
+		}
+	}
+
+	return ""
+}
+
+// mappingValue returns the value of `key` in the mapping `node`, or nil if it does not exist.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// sequenceItems returns the items of the sequence `node`, or nil if it is not a sequence.
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	return node.Content
+}
+
+// returns the value of a scalar node that should be validated, or an empty string if the node is
+// missing, is not a scalar, or is a script that can only be evaluated at runtime.
+func staticValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	value := strings.TrimSpace(node.Value)
+	if strings.HasPrefix(value, "{{") && strings.HasSuffix(value, "}}") {
+		return ""
+	}
+
+	return value
+}
+
+func (v *workflowValidator) collectTaskIds() {
+	for _, doc := range v.documents {
+		for _, task := range sequenceItems(mappingValue(doc.root, "tasks")) {
+			node := mappingValue(task, "id")
+			id := strings.ToLower(staticValue(node))
+
+			if id == "" && node != nil && node.Value != "" {
+				v.skipTaskReferences = true
+			}
+
+			if id != "" && v.taskNodes[id] == nil {
+				v.taskNodes[id] = mappingValue(task, "id")
+				v.taskDocs[id] = doc
+			}
+		}
+	}
+}
+
+func (v *workflowValidator) checkTaskReference(doc *configDocument, node *yaml.Node) {
+	id := staticValue(node)
+
+	if id != "" && !v.skipTaskReferences && v.taskNodes[strings.ToLower(id)] == nil {
+		v.addError(doc, node, messages.ValidationUnknownTask(id))
+	}
+}
+
+func (v *workflowValidator) checkTaskReferences(doc *configDocument, refs []*yaml.Node) {
+	for _, ref := range refs {
+		if parallel := mappingValue(ref, "parallel"); parallel != nil {
+			v.checkTaskReferences(doc, sequenceItems(parallel))
+			continue
+		}
+
+		v.checkTaskReference(doc, mappingValue(ref, "task"))
+	}
+}
+
+func (v *workflowValidator) checkTasks(doc *configDocument) {
+	for _, task := range sequenceItems(mappingValue(doc.root, "tasks")) {
+		for _, platform := range sequenceItems(mappingValue(task, "platforms")) {
+			name := staticValue(platform)
+
+			// an unknown platform is most likely a typo, and the task still runs on its other platforms
+			if name != "" && !utils.StringArrayContains(consts.KNOWN_PLATFORMS, strings.ToLower(name)) {
+				v.addError(doc, platform, messages.ValidationUnknownPlatform(name, consts.ALL_PLATFORMS)).Warning = true
+			}
+		}
+
+		for _, dependency := range sequenceItems(mappingValue(task, "depends-on")) {
+			v.checkTaskReference(doc, dependency)
+		}
+
+		v.checkHooks(doc, mappingValue(task, "hooks"))
+	}
+}
+
+// checkHooks checks that the hooks in the mapping `hooks` that are not scripts refer to tasks.
+func (v *workflowValidator) checkHooks(doc *configDocument, hooks *yaml.Node) {
+	if hooks == nil || hooks.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 1; i < len(hooks.Content); i += 2 {
+		v.checkTaskReference(doc, hooks.Content[i])
+	}
+}
+
+func (v *workflowValidator) checkScheduler(doc *configDocument) {
+	for _, st := range sequenceItems(mappingValue(doc.root, "scheduler")) {
+		v.checkTaskReference(doc, mappingValue(st, "task"))
+
+		node := mappingValue(st, "cron")
+		if expr := staticValue(node); expr != "" {
+			if _, err := cron.ParseStandard(expr); err != nil {
+				v.addError(doc, node, messages.ValidationInvalidCron(expr, err.Error()))
+			}
+		}
+	}
+}
+
+func (v *workflowValidator) checkWatchers(doc *configDocument) {
+	for _, wt := range sequenceItems(mappingValue(doc.root, "watchers")) {
+		v.checkTaskReference(doc, mappingValue(wt, "task"))
+
+		for _, section := range []string{"paths", "ignore"} {
+			for _, node := range sequenceItems(mappingValue(wt, section)) {
+				if pattern := staticValue(node); pattern != "" {
+					if err := watcher.ValidatePattern(pattern); err != nil {
+						v.addError(doc, node, messages.ValidationInvalidPattern(pattern, err.Error()))
+					}
+				}
+			}
+		}
+
+		for _, field := range []string{"debounce", "interval"} {
+			node := mappingValue(wt, field)
+			if value := staticValue(node); value != "" {
+				if _, err := time.ParseDuration(value); err != nil {
+					v.addError(doc, node, messages.ValidationInvalidDuration(value))
+				}
+			}
+		}
+	}
+}
+
+func (v *workflowValidator) checkNotificationRules(doc *configDocument) {
+	rules := mappingValue(mappingValue(mappingValue(doc.root, "settings"), "notifications"), "rules")
+
+	for _, rule := range sequenceItems(rules) {
+		for _, task := range sequenceItems(mappingValue(rule, "tasks")) {
+			v.checkTaskReference(doc, task)
+		}
+
+		node := mappingValue(rule, "on")
+		on := strings.ToLower(staticValue(node))
+		if on != "" && !utils.StringArrayContains(notificationRuleEvents(), on) {
+			v.addError(doc, node, messages.ValidationUnknownRuleEvent(on, notificationRuleEvents()))
+		}
+
+		if channel := mappingValue(rule, "channel"); staticValue(channel) != "" {
+			if name := strings.ToLower(staticValue(channel)); !utils.StringArrayContains(notificationChannels, name) {
+				v.addError(doc, channel, messages.NotificationUnknownChannel(name, notificationChannels))
+			}
+		}
+
+		duration := mappingValue(rule, "duration")
+		if value := staticValue(duration); value != "" {
+			if _, err := time.ParseDuration(value); err != nil {
+				v.addError(doc, duration, messages.ValidationInvalidDuration(value))
+			}
+		} else if on == RuleEventTaskSlow {
+			v.addError(doc, node, messages.ValidationMissingRuleDuration(on))
+		}
+	}
+}
+
+func (v *workflowValidator) checkPreconditions(doc *configDocument) {
+	for _, pc := range sequenceItems(mappingValue(doc.root, "preconditions")) {
+		v.checkTaskReference(doc, mappingValue(pc, "on-fail"))
+	}
+}
+
+// checkDependencyCycles reports each dependency cycle at the location of the first task in the cycle.
+func (v *workflowValidator) checkDependencyCycles(workflow *StackupWorkflow) {
+	for _, err := range workflow.CheckDependencies() {
+		var cycleErr *DependencyCycleError
+		if !errors.As(err, &cycleErr) {
+			continue
+		}
+
+		id := strings.ToLower(cycleErr.TaskIds[0])
+		if doc := v.taskDocs[id]; doc != nil {
+			v.addError(doc, v.taskNodes[id], err.Error())
+		}
+	}
+}
+
+func (v *workflowValidator) validate(workflow *StackupWorkflow) {
+	v.collectTaskIds()
+
+	for _, doc := range v.documents {
+		v.checkTasks(doc)
+		v.checkPreconditions(doc)
+		v.checkScheduler(doc)
+		v.checkWatchers(doc)
+		v.checkNotificationRules(doc)
+		v.checkHooks(doc, mappingValue(doc.root, "hooks"))
+
+		for _, section := range []string{"startup", "shutdown", "servers"} {
+			v.checkTaskReferences(doc, sequenceItems(mappingValue(doc.root, section)))
+		}
+	}
+
+	v.checkDependencyCycles(workflow)
+}
+
+// ValidateWorkflow checks the configuration file and its includes for syntax errors, unknown fields,
+// references to tasks that do not exist, invalid cron expressions, watcher patterns and platform names, and
+// dependency cycles.
+func (a *Application) ValidateWorkflow() []*ValidationError {
+	return validateWorkflowFile(a.ConfigFilename, a.Workflow)
+}
+
+// validateWorkflowFile validates the configuration file `filename`, which `workflow` was loaded from.
+func validateWorkflowFile(filename string, workflow *StackupWorkflow) []*ValidationError {
+	v := newWorkflowValidator()
+	v.skipTaskReferences = workflow.hasFailedIncludes()
+
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return []*ValidationError{{Filename: filename, Message: messages.ConfigFileNotReadable()}}
+	}
+
+	v.addDocument(filename, contents, reflect.TypeOf(StackupWorkflow{}))
+
+	sources := workflow.getIncludedSources()
+	sort.Slice(sources, func(i, j int) bool { return sources[i].name < sources[j].name })
+
+	for _, source := range sources {
+		v.addDocument(source.name, []byte(source.contents), reflect.TypeOf(IncludedTemplate{}))
+	}
+
+	v.validate(workflow)
+
+	return v.errors
+}
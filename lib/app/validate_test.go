@@ -0,0 +1,163 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func validateConfig(t *testing.T, contents string) []*app.ValidationError {
+	filename := filepath.Join(t.TempDir(), "stackup.yaml")
+	os.WriteFile(filename, []byte(contents), 0644)
+
+	a := &app.Application{ConfigFilename: filename, Workflow: app.CreateWorkflow(nil, nil)}
+
+	return a.ValidateWorkflow()
+}
+
+func TestValidateWorkflowReportsErrorLocations(t *testing.T) {
+	errs := validateConfig(t, `name: test
+tasks:
+  - id: build
+    command: make
+    platforms: [freebsd, linx]
+    depends_on: [lint]
+startup:
+  - task: missing
+scheduler:
+  - task: build
+    cron: "61 * * * *"
+`)
+
+	assert.Len(t, errs, 4)
+
+	assert.Equal(t, 6, errs[0].Line)
+	assert.True(t, errs[0].Warning)
+	assert.Contains(t, errs[0].Message, "depends_on")
+	assert.Contains(t, errs[0].Message, "depends-on")
+
+	assert.Equal(t, 5, errs[1].Line)
+	assert.Equal(t, 26, errs[1].Column)
+	assert.True(t, errs[1].Warning)
+	assert.Contains(t, errs[1].Message, "linx")
+
+	assert.Equal(t, 11, errs[2].Line)
+	assert.Contains(t, errs[2].Error(), "stackup.yaml:11:11: ")
+
+	assert.Equal(t, 8, errs[3].Line)
+	assert.Contains(t, errs[3].Message, "missing")
+}
+
+func TestValidateWorkflowSkipsScripts(t *testing.T) {
+	errs := validateConfig(t, `name: test
+tasks:
+  - id: build
+    command: make
+    platforms: ['{{ env("PLATFORM") }}']
+scheduler:
+  - task: build
+    cron: '{{ env("BUILD_SCHEDULE") }}'
+`)
+
+	assert.Empty(t, errs)
+}
+
+func TestValidateWorkflowReportsWatcherErrors(t *testing.T) {
+	errs := validateConfig(t, `name: test
+tasks:
+  - id: build
+    command: make
+watchers:
+  - task: build
+    paths: ['src/[a', composer.json]
+    ignore: [vendor]
+    debounce: soon
+    interval: often
+  - task: lint
+    paths: [src]
+`)
+
+	assert.Len(t, errs, 4)
+
+	assert.Equal(t, 7, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "src/[a")
+
+	assert.Equal(t, 9, errs[1].Line)
+	assert.Contains(t, errs[1].Message, "soon")
+
+	assert.Equal(t, 10, errs[2].Line)
+	assert.Contains(t, errs[2].Message, "often")
+
+	assert.Equal(t, 11, errs[3].Line)
+	assert.Contains(t, errs[3].Message, "lint")
+}
+
+func TestValidateWorkflowReportsNotificationRuleErrors(t *testing.T) {
+	errs := validateConfig(t, `name: test
+tasks:
+  - id: build
+    command: make
+settings:
+  notifications:
+    rules:
+      - on: task.crashed
+        channel: slack
+      - on: task.slow
+        tasks: [build]
+        channel: pager
+`)
+
+	assert.Len(t, errs, 3)
+
+	assert.Equal(t, 8, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "task.crashed")
+
+	assert.Equal(t, 12, errs[1].Line)
+	assert.Contains(t, errs[1].Message, "pager")
+
+	assert.Equal(t, 10, errs[2].Line)
+	assert.Contains(t, errs[2].Message, "duration")
+}
+
+func TestValidateWorkflowReportsUnknownHookTasks(t *testing.T) {
+	errs := validateConfig(t, `name: test
+tasks:
+  - id: build
+    command: make
+    hooks:
+      before: lint
+      after: '{{ true }}'
+hooks:
+  on-ready: build
+  on-error: notify
+`)
+
+	assert.Len(t, errs, 2)
+
+	assert.Equal(t, 6, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "lint")
+
+	assert.Equal(t, 10, errs[1].Line)
+	assert.Contains(t, errs[1].Message, "notify")
+}
+
+func TestGatewayContentTypesAreLoaded(t *testing.T) {
+	config := `name: test
+settings:
+  gateway:
+    content-types:
+      allowed: ['application/json']
+      blocked: ['text/html']
+`
+
+	workflow := app.CreateWorkflow(nil, nil)
+	assert.NoError(t, yaml.Unmarshal([]byte(config), workflow))
+	assert.Equal(t, []string{"application/json"}, workflow.Settings.Gateway.ContentTypes.Allowed)
+	assert.Equal(t, []string{"text/html"}, workflow.Settings.Gateway.ContentTypes.Blocked)
+
+	assert.Empty(t, validateConfig(t, config))
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/consts"
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
+	"github.com/stackup-app/stackup/lib/watcher"
+)
+
+// getDebounce returns how long to wait after a change before running the task, so that several changes
+// saved together only run it once.
+func (wt *WatchedTask) getDebounce() time.Duration {
+	if wt.Debounce == "" {
+		return consts.DEFAULT_WATCH_DEBOUNCE_MS * time.Millisecond
+	}
+
+	result, err := time.ParseDuration(wt.Debounce)
+	if err != nil {
+		support.WarningMessage(messages.TaskInvalidDuration(wt.TaskId(), "watcher debounce", wt.Debounce))
+		return consts.DEFAULT_WATCH_DEBOUNCE_MS * time.Millisecond
+	}
+
+	return result
+}
+
+// getInterval returns how often the watcher's paths are checked for changes.  Directories and glob patterns
+// are read recursively on each check, so they are checked less often by default.
+func (wt *WatchedTask) getInterval(watchesDirectories bool) time.Duration {
+	fallback := consts.WATCH_INTERVAL_MS * time.Millisecond
+	if watchesDirectories {
+		fallback = consts.DIR_WATCH_INTERVAL_MS * time.Millisecond
+	}
+
+	if wt.Interval == "" {
+		return fallback
+	}
+
+	result, err := time.ParseDuration(wt.Interval)
+	if err != nil || result <= 0 {
+		support.WarningMessage(messages.TaskInvalidDuration(wt.TaskId(), "watcher interval", wt.Interval))
+		return fallback
+	}
+
+	return result
+}
+
+// getIgnorePatterns returns the watcher's ignore patterns, including the directories that are never watched.
+func (wt *WatchedTask) getIgnorePatterns() []string {
+	return append(append([]string{}, consts.DEFAULT_WATCH_IGNORE...), wt.Ignore...)
+}
+
+func (a *Application) createFileWatchers() {
+	if len(a.Workflow.Watchers) == 0 {
+		return
+	}
+
+	support.StatusMessage("Creating file watchers...", false)
+
+	a.startFileWatchers()
+
+	support.PrintCheckMarkLine()
+}
+
+// startFileWatchers starts watching the paths of each of the workflow's watchers.  Paths are relative to the
+// directory of the configuration file.
+func (a *Application) startFileWatchers() {
+	base := filepath.Dir(a.ConfigFilename)
+
+	for _, def := range a.Workflow.Watchers {
+		def.Workflow = a.Workflow
+		def.JsEngine = a.JsEngine
+
+		taskId := def.TaskId()
+
+		if _, found := a.Workflow.GetTaskById(taskId); !found {
+			support.FailureMessageWithXMark(messages.TaskNotFound(taskId))
+			continue
+		}
+
+		w := watcher.New(consts.WATCH_INTERVAL_MS*time.Millisecond, def.getDebounce(), func(changed []string) {
+			a.runWatchedTask(taskId, changed)
+		})
+
+		if err := w.SetPaths(base, def.Paths, def.getIgnorePatterns()); err != nil {
+			support.FailureMessageWithXMark(messages.WatcherInvalidPattern(taskId, err.Error()))
+			continue
+		}
+
+		w.SetInterval(def.getInterval(w.WatchesDirectories()))
+		w.Start()
+		a.fileWatchers = append(a.fileWatchers, w)
+	}
+}
+
+func (a *Application) stopFileWatchers() {
+	for _, w := range a.fileWatchers {
+		w.Stop()
+	}
+
+	a.fileWatchers = nil
+}
+
+// runWatchedTask restarts the task's server if it is one, and otherwise runs the task, on the event loop.
+// Changes made while the task runs are reported once it has finished, so the task never runs more than
+// once at a time for the same watcher.
+func (a *Application) runWatchedTask(taskId string, changed []string) {
+	filenames := a.displayFilenames(changed)
+
+	support.StatusMessageLine(messages.WatchedFilesChanged(taskId, filenames), true)
+	events.Emit(events.Event{Type: events.WatcherTriggered, Task: taskId, Message: strings.Join(filenames, ", ")})
+
+	a.runOnEventLoop(func() {
+		if server, found := a.Supervisor.FindServer(taskId); found {
+			if _, err := a.restartServerUsing(server, a.startServerOnLoop); err != nil {
+				support.FailureMessageWithXMark(err.Error())
+			}
+
+			return
+		}
+
+		// the task is found when it runs, so that its current definition is used after a reload
+		if task, found := a.Workflow.GetTaskById(taskId); found {
+			task.RunSync()
+		}
+	})
+}
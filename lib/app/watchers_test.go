@@ -0,0 +1,58 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchersRestartServers(t *testing.T) {
+	requirePosixShell(t)
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "node_modules"), 0755)
+
+	config := writeConfig(t, dir, "stackup.yaml", `
+name: watcher test
+tasks:
+  - id: web
+    command: sh -c 'exec sleep 30'
+    path: $DIR
+    stop-timeout: 1s
+servers:
+  - task: web
+watchers:
+  - task: web
+    paths: ['.']
+    debounce: 50ms
+    interval: 50ms
+`)
+
+	a := app.NewApplication()
+	a.ConfigFilename = config
+	a.Workflow = app.CreateWorkflow(nil, &sync.Map{})
+	defer a.Supervisor.StopAll(nil, nil)
+	go a.RunEventLoop()
+
+	// the first reload starts the server and the watcher
+	assert.NoError(t, a.ReloadWorkflow())
+
+	pid := a.GetStatus().Servers[0].Pid
+
+	// node_modules is never watched
+	os.WriteFile(filepath.Join(dir, "node_modules", "index.js"), []byte("\n"), 0644)
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, pid, a.GetStatus().Servers[0].Pid)
+
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("APP_ENV=local\n"), 0644)
+
+	assert.Eventually(t, func() bool {
+		status := a.GetStatus()
+		return status.Servers[0].State == app.ServerRunning && status.Servers[0].Pid != pid
+	}, 10*time.Second, 50*time.Millisecond)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/stackup-app/stackup/lib/checksums"
 	"github.com/stackup-app/stackup/lib/consts"
 	"github.com/stackup-app/stackup/lib/debug"
+	"github.com/stackup-app/stackup/lib/events"
 	"github.com/stackup-app/stackup/lib/gateway"
 	"github.com/stackup-app/stackup/lib/messages"
 	"github.com/stackup-app/stackup/lib/scripting"
@@ -34,6 +35,8 @@ type StackupWorkflow struct {
 	Shutdown       []*TaskReference        `yaml:"shutdown"`
 	Servers        []*TaskReference        `yaml:"servers"`
 	Scheduler      []*ScheduledTask        `yaml:"scheduler"`
+	Watchers       []*WatchedTask          `yaml:"watchers"`
+	Hooks          WorkflowHooks           `yaml:"hooks"`
 	Includes       []WorkflowInclude       `yaml:"includes"`
 	Debug          bool                    `yaml:"debug"`
 	State          WorkflowState
@@ -42,7 +45,17 @@ type StackupWorkflow struct {
 	Gateway        *gateway.Gateway
 	ProcessMap     *sync.Map
 	CommandStartCb types.CommandCallback
-	ExitAppFunc    func()
+	KillCommandCb  types.CommandCallback
+	// exits the application when an include fails checksum verification; nil while the workflow is
+	// reloaded, so that the include is rejected instead
+	ExitAppFunc func()
+	// when true, command output is emitted as events instead of being written to stdout and stderr
+	JsonOutput bool
+	// when true, command output is only kept in each task's logs, which are displayed by the dashboard
+	CaptureOutput  bool
+	sources        []includedSource
+	failedIncludes int
+	includeLock    sync.Mutex
 	types.AppWorkflowContract
 }
 
@@ -51,7 +64,7 @@ func CreateWorkflow(gw *gateway.Gateway, processMap *sync.Map) *StackupWorkflow
 		Settings:      &settings.Settings{},
 		Preconditions: []*WorkflowPrecondition{},
 		Tasks:         []*Task{},
-		State:         WorkflowState{},
+		State:         NewWorkflowState(),
 		Includes:      []WorkflowInclude{},
 		Gateway:       gw,
 		ProcessMap:    processMap,
@@ -128,6 +141,10 @@ func (workflow *StackupWorkflow) InitializeSections() {
 		st.Initialize(workflow)
 	}
 
+	for _, wt := range workflow.Watchers {
+		wt.Initialize(workflow)
+	}
+
 	for _, pc := range workflow.Preconditions {
 		pc.Initialize(workflow)
 	}
@@ -144,6 +161,11 @@ func (workflow *StackupWorkflow) ConfigureDefaultSettings() {
 
 	workflow.expandEnvVars(&workflow.Settings.Notifications.Slack.ChannelIds)
 	workflow.expandEnvVars(&workflow.Settings.Notifications.Telegram.ChatIds)
+	workflow.expandEnvVars(&workflow.Settings.Notifications.Email.To)
+
+	for i := range workflow.Settings.Notifications.Rules {
+		workflow.expandEnvVars(&workflow.Settings.Notifications.Rules[i].To)
+	}
 
 	for _, host := range workflow.Settings.Domains.Hosts {
 		if host.Gateway == "allow" || host.Gateway == "" {
@@ -200,7 +222,12 @@ func (workflow *StackupWorkflow) processIncludes() {
 		wgLoadIncludes.Add(1)
 		go func(inc WorkflowInclude) {
 			defer wgLoadIncludes.Done()
-			workflow.processInclude(&inc)
+
+			if err := workflow.processInclude(&inc); err != nil {
+				workflow.includeLock.Lock()
+				workflow.failedIncludes++
+				workflow.includeLock.Unlock()
+			}
 		}(include)
 	}
 	wgLoadIncludes.Wait()
@@ -212,7 +239,9 @@ func (workflow *StackupWorkflow) getIncludedUrls() []string {
 	result := []string{}
 
 	for _, include := range workflow.Includes {
-		result = append(result, include.FullUrl())
+		if include.IncludeType() != IncludeTypeFile {
+			result = append(result, include.FullUrl())
+		}
 	}
 
 	return utils.GetUniqueStrings(result)
@@ -235,6 +264,32 @@ func (workflow *StackupWorkflow) tryLoadingCachedData(include *WorkflowInclude)
 	return loaded
 }
 
+// loadLocalFileInclude reads a `file` include from disk.  Local files are not cached, so that changes to
+// them are used when the workflow is reloaded.
+func (workflow *StackupWorkflow) loadLocalFileInclude(include *WorkflowInclude) (error, bool) {
+	contents, err := os.ReadFile(include.Filename())
+	if err != nil {
+		return err, false
+	}
+
+	include.SetContents(string(contents), false)
+
+	return nil, true
+}
+
+// getLocalIncludeFilenames returns the absolute paths of the workflow's `file` includes.
+func (workflow *StackupWorkflow) getLocalIncludeFilenames() []string {
+	result := []string{}
+
+	for _, include := range workflow.Includes {
+		if include.IncludeType() == IncludeTypeFile {
+			result = append(result, include.Filename())
+		}
+	}
+
+	return result
+}
+
 func (workflow *StackupWorkflow) loadRemoteFileInclude(include *WorkflowInclude) (error, bool) {
 	var err error = nil
 	var contents string
@@ -251,7 +306,11 @@ func (workflow *StackupWorkflow) loadRemoteFileInclude(include *WorkflowInclude)
 func (workflow *StackupWorkflow) handleChecksumVerification(include *WorkflowInclude) bool {
 	var result bool = include.ValidateChecksum()
 
-	if include.ValidationState.IsMismatch() && workflow.Settings.ExitOnChecksumMismatch {
+	if include.ValidationState.IsMismatch() {
+		events.Emit(events.Event{Type: events.IncludeChecksumMismatch, Name: include.DisplayName()})
+	}
+
+	if include.ValidationState.IsMismatch() && workflow.Settings.ExitOnChecksumMismatch && workflow.ExitAppFunc != nil {
 		support.FailureMessageWithXMark(messages.ExitDueToChecksumMismatch())
 		workflow.ExitAppFunc()
 	}
@@ -259,15 +318,29 @@ func (workflow *StackupWorkflow) handleChecksumVerification(include *WorkflowInc
 	return result
 }
 
-func (workflow *StackupWorkflow) loadAndImportInclude(rawYaml string) error {
+func (workflow *StackupWorkflow) loadAndImportInclude(include *WorkflowInclude) error {
 	var template IncludedTemplate
 
-	if err := yaml.Unmarshal([]byte(rawYaml), &template); err != nil {
+	if err := yaml.Unmarshal([]byte(include.Contents), &template); err != nil {
 		return err
 	}
 
 	template.Initialize(workflow)
 
+	for _, task := range template.Tasks {
+		task.IncludedFrom = include.DisplayName()
+		task.FromRemote = include.IncludeType() != IncludeTypeFile
+	}
+
+	// includes are loaded concurrently
+	workflow.includeLock.Lock()
+	defer workflow.includeLock.Unlock()
+
+	workflow.sources = append(workflow.sources, includedSource{
+		name:     utils.FirstNonEmpty(include.File, include.DisplayName()),
+		contents: include.Contents,
+	})
+
 	workflow.Tasks = append(workflow.Tasks, template.Tasks...)
 	workflow.Preconditions = append(workflow.Preconditions, template.Preconditions...)
 	workflow.Startup = append(workflow.Startup, template.Startup...)
@@ -278,35 +351,73 @@ func (workflow *StackupWorkflow) loadAndImportInclude(rawYaml string) error {
 	return nil
 }
 
+// returns the contents of each include that has been loaded.
+func (workflow *StackupWorkflow) getIncludedSources() []includedSource {
+	workflow.includeLock.Lock()
+	defer workflow.includeLock.Unlock()
+
+	return append([]includedSource{}, workflow.sources...)
+}
+
+func (workflow *StackupWorkflow) hasFailedIncludes() bool {
+	workflow.includeLock.Lock()
+	defer workflow.includeLock.Unlock()
+
+	return workflow.failedIncludes > 0
+}
+
 func (workflow *StackupWorkflow) processInclude(include *WorkflowInclude) error {
 	include.Initialize(workflow)
 
 	var err error = nil
-	loaded := workflow.tryLoadingCachedData(include)
+	var loaded bool
 
-	if !loaded {
+	if include.IncludeType() == IncludeTypeFile {
+		err, loaded = workflow.loadLocalFileInclude(include)
+		if !loaded {
+			support.FailureMessageWithXMark(messages.RemoteIncludeStatus("failed: "+err.Error(), include.DisplayName()))
+			events.Emit(events.Event{Type: events.IncludeFailed, Name: include.DisplayName(), Message: err.Error()})
+			return err
+		}
+	} else if loaded = workflow.tryLoadingCachedData(include); !loaded {
 		debug.Logf("include not loaded from cache: %s", include.DisplayName())
 
 		err, loaded = workflow.loadRemoteFileInclude(include)
 		if !loaded {
 			support.FailureMessageWithXMark(messages.RemoteIncludeStatus("rejected: "+err.Error(), include.DisplayName()))
+			events.Emit(events.Event{Type: events.IncludeFailed, Name: include.DisplayName(), Message: err.Error()})
 			return err
 		}
 	}
 
 	if !loaded {
 		support.FailureMessageWithXMark(messages.RemoteIncludeStatus("failed", include.DisplayName()))
+		events.Emit(events.Event{Type: events.IncludeFailed, Name: include.DisplayName()})
 		return errors.New(messages.RemoteIncludeCannotLoad(include.DisplayName()))
 	}
 
-	if err := workflow.loadAndImportInclude(include.Contents); err != nil {
+	if err := workflow.loadAndImportInclude(include); err != nil {
 		support.FailureMessageWithXMark(messages.RemoteIncludeStatus("cache load failed", include.DisplayName()))
+		events.Emit(events.Event{Type: events.IncludeFailed, Name: include.DisplayName(), Message: err.Error()})
 		return err
 	}
 
-	if !workflow.handleChecksumVerification(include) {
-		// the app terminiates during handleChecksumVerification if the 'exit-on-checksum-mismatch' setting is enabled
-		// so we can only show a wanring message here.
+	verified := workflow.handleChecksumVerification(include)
+	events.Emit(events.Event{
+		Type:     events.IncludeLoaded,
+		Name:     include.DisplayName(),
+		Status:   include.loadedSourceText(),
+		Checksum: include.ValidationState.String(),
+	})
+
+	// the app terminates during handleChecksumVerification if the 'exit-on-checksum-mismatch' setting is
+	// enabled, unless the workflow is being reloaded, in which case the include fails instead
+	if !verified && workflow.Settings.ExitOnChecksumMismatch {
+		support.FailureMessageWithXMark(messages.RemoteIncludeChecksumMismatch(include.DisplayName()))
+		return errors.New(messages.RemoteIncludeChecksumMismatch(include.DisplayName()))
+	}
+
+	if !verified {
 		support.WarningMessage(messages.RemoteIncludeChecksumMismatch(include.DisplayName()))
 		return nil
 	}
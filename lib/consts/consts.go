@@ -15,8 +15,56 @@ var DEFAULT_GATEWAY_MIDDLEWARE = []string{"validateUrl", "verifyFileType", "vali
 
 const MAX_TASK_RUNS = 99999999
 
+const DEFAULT_RETRY_DELAY_SECONDS = 1
+const MAX_RETRY_DELAY_SECONDS = 300
+const DEFAULT_MAX_RESTARTS = 10
+const DEFAULT_READY_TIMEOUT_SECONDS = 30
+const DEFAULT_READY_INTERVAL_MS = 250
+const DEFAULT_STOP_TIMEOUT_SECONDS = 10
+
+// the exit code of `stackup run` when the task is skipped, so it can't be mistaken for a successful run
+const RUN_SKIPPED_EXIT_CODE = 3
+
+// the number of lines of each task's most recent output that are kept for the control API
+const MAX_TASK_LOG_LINES = 1000
+
+// the number of lines displayed by the `logs` command by default
+const DEFAULT_LOG_LINES = 100
+
+// how often the configuration files are checked for changes, and how long to wait after a change before
+// reloading the workflow, so that several changes saved together are applied at once
+const CONFIG_WATCH_INTERVAL_MS = 500
+const CONFIG_WATCH_DEBOUNCE_MS = 300
+
+// how often the paths of `watchers` are checked for changes by default, how long to wait after a change
+// before running the task by default, and the directories that are never watched.  Directories and glob
+// patterns are read recursively on each check, so they are checked less often.
+const WATCH_INTERVAL_MS = 500
+const DIR_WATCH_INTERVAL_MS = 2000
+const DEFAULT_WATCH_DEBOUNCE_MS = 500
+
+// how long to wait for notifications to be delivered when the application exits
+const NOTIFICATION_DELIVERY_TIMEOUT_SECONDS = 5
+
+// how many notifications can wait to be sent on each channel, the minimum time between notifications on the
+// same channel, how often and how soon a notification that could not be sent is retried (the wait doubles
+// after each attempt), and how long the same notification is not sent again for
+const NOTIFICATION_QUEUE_SIZE = 100
+const NOTIFICATION_RATE_LIMIT_MS = 1000
+const NOTIFICATION_RETRIES = 3
+const NOTIFICATION_RETRY_BACKOFF_MS = 1000
+const NOTIFICATION_DEDUP_WINDOW_SECONDS = 60
+
+var DEFAULT_WATCH_IGNORE = []string{".git", "node_modules"}
+
 var ALL_PLATFORMS = []string{"windows", "linux", "darwin"}
 
+// every operating system that stackup can be built for, as reported by runtime.GOOS
+var KNOWN_PLATFORMS = []string{
+	"aix", "android", "darwin", "dragonfly", "freebsd", "illumos", "ios", "js",
+	"linux", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows",
+}
+
 var DEFAULT_ALLOWED_DOMAINS = []string{"raw.githubusercontent.com", "api.github.com"}
 var DISPLAY_URLS_REMOVABLE = []string{"https://", "github.com", "raw.githubusercontent.com", "s3:"}
 
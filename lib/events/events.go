@@ -0,0 +1,117 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	WorkflowStarted  = "workflow.started"
+	WorkflowReady    = "workflow.ready"
+	WorkflowStopping = "workflow.stopping"
+
+	WorkflowReloaded     = "workflow.reloaded"
+	WorkflowReloadFailed = "workflow.reload-failed"
+
+	IncludeLoaded           = "include.loaded"
+	IncludeFailed           = "include.failed"
+	IncludeChecksumMismatch = "include.checksum-mismatch"
+
+	PreconditionPassed = "precondition.passed"
+	PreconditionFailed = "precondition.failed"
+
+	TaskStarted  = "task.started"
+	TaskFinished = "task.finished"
+	TaskSkipped  = "task.skipped"
+	TaskRetrying = "task.retrying"
+	TaskOutput   = "task.output"
+
+	ServerStarted    = "server.started"
+	ServerReady      = "server.ready"
+	ServerNotReady   = "server.not-ready"
+	ServerExited     = "server.exited"
+	ServerRestarting = "server.restarting"
+	ServerStopped    = "server.stopped"
+
+	WatcherTriggered = "watcher.triggered"
+
+	NotificationSent   = "notification.sent"
+	NotificationFailed = "notification.failed"
+)
+
+// Event is something that happened while running the workflow.  Only the fields that are relevant to
+// the event's type are set.
+type Event struct {
+	Type       string    `json:"event"`
+	Time       time.Time `json:"time"`
+	Task       string    `json:"task,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	Checksum   string    `json:"checksum,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	ExitCode   *int      `json:"exit-code,omitempty"`
+	DurationMs *int64    `json:"duration-ms,omitempty"`
+	Pid        int       `json:"pid,omitempty"`
+	Attempt    int       `json:"attempt,omitempty"`
+	Stream     string    `json:"stream,omitempty"`
+	Line       string    `json:"line,omitempty"`
+}
+
+// Handler is called for every event that is emitted.
+type Handler func(event Event)
+
+var (
+	lock     sync.RWMutex
+	handlers = map[int]Handler{}
+	nextId   int
+)
+
+// Subscribe calls `handler` for every event emitted from now on, until the returned function is called.
+func Subscribe(handler Handler) func() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	id := nextId
+	nextId++
+	handlers[id] = handler
+
+	return func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		delete(handlers, id)
+	}
+}
+
+// Emit calls each subscribed handler with `event`, setting its time if it has not been set.
+func Emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	lock.RLock()
+	current := make([]Handler, 0, len(handlers))
+	for _, handler := range handlers {
+		current = append(current, handler)
+	}
+	lock.RUnlock()
+
+	for _, handler := range current {
+		handler(event)
+	}
+}
+
+// WithExitCode returns a copy of `event` with its exit code set.
+func (e Event) WithExitCode(code int) Event {
+	e.ExitCode = &code
+
+	return e
+}
+
+// WithDuration returns a copy of `event` with its duration set.
+func (e Event) WithDuration(d time.Duration) Event {
+	ms := d.Milliseconds()
+	e.DurationMs = &ms
+
+	return e
+}
@@ -0,0 +1,61 @@
+package events_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	received := []events.Event{}
+	unsubscribe := events.Subscribe(func(event events.Event) {
+		received = append(received, event)
+	})
+
+	events.Emit(events.Event{Type: events.TaskStarted, Task: "build"})
+	unsubscribe()
+	events.Emit(events.Event{Type: events.TaskFinished, Task: "build"})
+
+	assert.Len(t, received, 1)
+	assert.Equal(t, events.TaskStarted, received[0].Type)
+	assert.False(t, received[0].Time.IsZero())
+}
+
+func TestJsonHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := events.NewJsonHandler(&buf)
+	at := time.Date(2023, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	handler(events.Event{Type: events.TaskFinished, Time: at, Task: "build", Status: "success"}.WithExitCode(0).WithDuration(1500 * time.Millisecond))
+	handler(events.Event{Type: events.PreconditionPassed, Time: at, Name: "php <installed>"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, `{"event":"task.finished","time":"2023-01-01T10:30:00Z","task":"build","status":"success","exit-code":0,"duration-ms":1500}`, lines[0])
+	assert.Equal(t, `{"event":"precondition.passed","time":"2023-01-01T10:30:00Z","name":"php <installed>"}`, lines[1])
+}
+
+func TestOutputWriter(t *testing.T) {
+	lines := []string{}
+	unsubscribe := events.Subscribe(func(event events.Event) {
+		data, _ := json.Marshal(map[string]string{"task": event.Task, "stream": event.Stream, "line": event.Line})
+		lines = append(lines, string(data))
+	})
+	defer unsubscribe()
+
+	w := events.NewOutputWriter("build", "stderr")
+	w.Write([]byte("one\r\ntw"))
+	w.Write([]byte("o\nthree"))
+	assert.Len(t, lines, 2)
+
+	w.Flush()
+	assert.Equal(t, []string{
+		`{"line":"one","stream":"stderr","task":"build"}`,
+		`{"line":"two","stream":"stderr","task":"build"}`,
+		`{"line":"three","stream":"stderr","task":"build"}`,
+	}, lines)
+}
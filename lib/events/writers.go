@@ -0,0 +1,73 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// NewJsonHandler returns a handler that writes each event to `w` as a single line of JSON.
+func NewJsonHandler(w io.Writer) Handler {
+	var lock sync.Mutex
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return func(event Event) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		encoder.Encode(event)
+	}
+}
+
+// OutputWriter is an io.Writer that emits a `task.output` event for each line written to it.  Incomplete
+// lines are buffered until they are completed or the writer is flushed.
+type OutputWriter struct {
+	task   string
+	stream string
+	buffer []byte
+	lock   sync.Mutex
+}
+
+// NewOutputWriter returns a writer for the output of `task` written to `stream`, such as "stdout".
+func NewOutputWriter(task string, stream string) *OutputWriter {
+	return &OutputWriter{task: task, stream: stream}
+}
+
+func (ow *OutputWriter) Write(p []byte) (int, error) {
+	ow.lock.Lock()
+	defer ow.lock.Unlock()
+
+	ow.buffer = append(ow.buffer, p...)
+
+	for {
+		index := bytes.IndexByte(ow.buffer, '\n')
+		if index == -1 {
+			break
+		}
+
+		ow.emit(ow.buffer[:index])
+		ow.buffer = ow.buffer[index+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush emits any incomplete line that has been buffered.
+func (ow *OutputWriter) Flush() error {
+	ow.lock.Lock()
+	defer ow.lock.Unlock()
+
+	if len(ow.buffer) > 0 {
+		ow.emit(ow.buffer)
+		ow.buffer = nil
+	}
+
+	return nil
+}
+
+func (ow *OutputWriter) emit(line []byte) {
+	Emit(Event{Type: TaskOutput, Task: ow.task, Stream: ow.stream, Line: string(bytes.TrimSuffix(line, []byte("\r")))})
+}
@@ -2,6 +2,8 @@ package messages
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/stackup-app/stackup/lib/types"
 )
@@ -10,6 +12,224 @@ func TaskNotFound(name string) string {
 	return fmt.Sprintf("Task %s not found.", name)
 }
 
+func TaskDependencyNotFound(taskId string, dependencyId string) string {
+	return fmt.Sprintf("Task %s depends on %s, which was not found.", taskId, dependencyId)
+}
+
+func TaskDependencyCycle(taskIds []string) string {
+	return "Task dependency cycle detected: " + strings.Join(taskIds, " -> ")
+}
+
+func TaskDependencyFailed(name string, dependencyName string) string {
+	return fmt.Sprintf("%s: dependency '%s' failed.", name, dependencyName)
+}
+
+func TaskEnvFileNotLoaded(name string, filename string) string {
+	return fmt.Sprintf("%s: unable to load env file '%s'.", name, filename)
+}
+
+func TaskTimedOut(name string, timeout string) string {
+	return fmt.Sprintf("%s: timed out after %s.", name, timeout)
+}
+
+func TaskInvalidDuration(name string, setting string, value string) string {
+	return fmt.Sprintf("%s: invalid %s '%s', expected a duration such as '90s' or '5m'.", name, setting, value)
+}
+
+func TaskRetrying(name string, attempt int, retries int, delay time.Duration) string {
+	return fmt.Sprintf("%s: failed, retrying in %s (retry %d of %d)...", name, delay, attempt, retries)
+}
+
+func TaskInvalidRestartPolicy(name string, policy string) string {
+	return fmt.Sprintf("%s: invalid restart policy '%s', expected 'never', 'on-failure' or 'always'.", name, policy)
+}
+
+func ServerExited(name string, status string) string {
+	return fmt.Sprintf("Server %s exited (%s).", name, status)
+}
+
+func ServerRestarting(name string, delay time.Duration, restart int, maxRestarts int) string {
+	return fmt.Sprintf("Restarting server %s in %s (restart %d of %d)...", name, delay, restart, maxRestarts)
+}
+
+func TaskInvalidReadyPattern(name string, pattern string) string {
+	return fmt.Sprintf("%s: invalid ready log pattern '%s'.", name, pattern)
+}
+
+func ServerWaitingUntilReady(name string) string {
+	return fmt.Sprintf("Waiting for %s to be ready...", name)
+}
+
+func ServerReady(name string) string {
+	return fmt.Sprintf("%s is ready", name)
+}
+
+func ServerNotReady(name string, timeout time.Duration) string {
+	return fmt.Sprintf("%s was not ready after %s.", name, timeout)
+}
+
+func ServerExitedBeforeReady(name string) string {
+	return fmt.Sprintf("%s exited before it was ready.", name)
+}
+
+func ServerNotStartedNotReady(name string, notReady string) string {
+	return fmt.Sprintf("%s: not started, %s is not ready.", name, notReady)
+}
+
+func WorkflowNotReady() string {
+	return "Not all servers are ready, skipping the on-ready hook."
+}
+
+func TaskInvalidStopSignal(name string, signal string) string {
+	return fmt.Sprintf("%s: unsupported stop signal '%s', using SIGTERM.", name, signal)
+}
+
+func ServerStopping(name string) string {
+	return fmt.Sprintf("Stopping %s...", name)
+}
+
+func ServerStopped(name string, status string) string {
+	return fmt.Sprintf("Stopped %s (%s)", name, status)
+}
+
+func ServerKilled(name string, timeout time.Duration) string {
+	return fmt.Sprintf("%s did not stop within %s and was killed.", name, timeout)
+}
+
+func TaskInvalidOutputMode(name string, mode string) string {
+	return fmt.Sprintf("%s: invalid output mode '%s', expected 'inherit', 'prefixed', 'file' or 'silent'.", name, mode)
+}
+
+func TaskLogFileRequired(name string) string {
+	return fmt.Sprintf("%s: the 'file' output mode requires a log-file.", name)
+}
+
+func TaskLogFileNotOpened(name string, filename string) string {
+	return fmt.Sprintf("%s: unable to open log file '%s'.", name, filename)
+}
+
+func RunningInParallel(names []string) string {
+	return "Running in parallel: " + strings.Join(names, ", ")
+}
+
+func RunCommandUsage() string {
+	return "usage: stackup run <task-id> [--set name=value ...]"
+}
+
+func RunCommandTaskSkipped(name string) string {
+	return fmt.Sprintf("%s was skipped and did not run.", name)
+}
+
+func DryRunCommandSkipped(command string) string {
+	return fmt.Sprintf("dry run: not running '%s'", command)
+}
+
+func DryRunScriptSkipped(action string) string {
+	return fmt.Sprintf("dry run: not calling %s", action)
+}
+
+func InvalidOutputFormat(format string) string {
+	return fmt.Sprintf("invalid output format '%s', expected 'text' or 'json'", format)
+}
+
+func ServerRestartRequested(name string) string {
+	return fmt.Sprintf("Restarting server %s...", name)
+}
+
+func RestartCommandUsage() string {
+	return "usage: stackup restart <server-task-id>"
+}
+
+func LogsCommandUsage() string {
+	return "usage: stackup logs <task-id> [--lines count]"
+}
+
+func NotRunning(configFilename string) string {
+	return fmt.Sprintf("StackUp is not running for %s.", configFilename)
+}
+
+func ControlApiAlreadyRunning(path string) string {
+	return fmt.Sprintf("Another instance is already using the control socket %s, the control API is disabled.", path)
+}
+
+func ControlApiNotStarted(reason string) string {
+	return fmt.Sprintf("The control API could not be started: %s", reason)
+}
+
+func ServerNotFound(id string) string {
+	return fmt.Sprintf("server '%s' was not found.", id)
+}
+
+func ServerNotStarted(name string) string {
+	return fmt.Sprintf("server %s could not be started.", name)
+}
+
+func ServerAlreadyRestarting(name string) string {
+	return fmt.Sprintf("server %s is already restarting.", name)
+}
+
+func TaskQueued(name string) string {
+	return fmt.Sprintf("Task %s will run next.", name)
+}
+
+func ShuttingDown() string {
+	return "Shutting down..."
+}
+
+func SchemaCommandUsage() string {
+	return "usage: stackup schema [config|include]"
+}
+
+func ConfigFileNotReadable() string {
+	return "unable to read configuration file."
+}
+
+func ConfigurationValid(filename string) string {
+	return fmt.Sprintf("%s is valid", filename)
+}
+
+func ConfigurationInvalid(count int) string {
+	return fmt.Sprintf("Found %d configuration error(s).", count)
+}
+
+func ValidationUnknownField(name string, parent string, suggestion string) string {
+	result := fmt.Sprintf("unknown field '%s'", name)
+
+	if parent != "" {
+		result += fmt.Sprintf(" in '%s'", parent)
+	}
+
+	if suggestion != "" {
+		result += fmt.Sprintf(", did you mean '%s'?", suggestion)
+	}
+
+	return result
+}
+
+func ValidationUnknownTask(id string) string {
+	return fmt.Sprintf("task '%s' is not defined", id)
+}
+
+func ValidationInvalidCron(expr string, reason string) string {
+	return fmt.Sprintf("invalid cron expression '%s': %s", expr, reason)
+}
+
+func ScheduledTaskNotCreated(taskId string, reason string) string {
+	return fmt.Sprintf("%s was not scheduled: %s", taskId, reason)
+}
+
+func ValidationInvalidPattern(pattern string, reason string) string {
+	return fmt.Sprintf("invalid pattern '%s': %s", pattern, reason)
+}
+
+func ValidationInvalidDuration(value string) string {
+	return fmt.Sprintf("invalid duration '%s', expected a duration such as '500ms' or '2s'", value)
+}
+
+func ValidationUnknownPlatform(name string, platforms []string) string {
+	return fmt.Sprintf("unknown platform '%s', expected an operating system such as: %s", name, strings.Join(platforms, ", "))
+}
+
 func NotExplicitlyAllowed(at types.AccessType, str string) string {
 	return fmt.Sprintf("Access to %s '%s' has not been explicitly allowed.", at.String(), str)
 }
@@ -37,3 +257,91 @@ func RemoteIncludeChecksumMismatch(name string) string {
 func RemoteIncludeCannotLoad(name string) string {
 	return "unable to load remote include: " + name
 }
+
+func ServerAlreadyRunning(name string) string {
+	return fmt.Sprintf("server %s is already running.", name)
+}
+
+func ServerStartRequested(name string) string {
+	return fmt.Sprintf("Starting server %s...", name)
+}
+
+func DashboardNotOpened(reason string) string {
+	return fmt.Sprintf("The dashboard could not be displayed: %s", reason)
+}
+
+func DashboardRequiresTerminal() string {
+	return "The dashboard requires a terminal and text output, it has been disabled."
+}
+
+func ConfigurationChanged(filenames []string) string {
+	return fmt.Sprintf("Configuration changed (%s), reloading...", strings.Join(filenames, ", "))
+}
+
+func ConfigurationReloaded(changes []string) string {
+	if len(changes) == 0 {
+		return "Configuration reloaded, no changes to apply."
+	}
+
+	return "Configuration reloaded: " + strings.Join(changes, ", ") + "."
+}
+
+func ConfigurationReloadRejected(reason string) string {
+	return fmt.Sprintf("The configuration was not reloaded: %s", reason)
+}
+
+func ConfigurationIncludeFailed() string {
+	return "one or more includes could not be loaded."
+}
+
+func WatchedFilesChanged(taskId string, filenames []string) string {
+	return fmt.Sprintf("Files changed (%s), running %s...", strings.Join(filenames, ", "), taskId)
+}
+
+func WatcherInvalidPattern(taskId string, reason string) string {
+	return fmt.Sprintf("The watcher for %s was not started: %s", taskId, reason)
+}
+
+func TaskHookFailed(taskName string, hook string) string {
+	return fmt.Sprintf("%s was not run because its %s hook failed.", taskName, hook)
+}
+
+func HookFailed(hook string) string {
+	return fmt.Sprintf("The %s hook failed.", hook)
+}
+
+func HookSkippedRecursion(hook string, taskName string) string {
+	return fmt.Sprintf("The %s hook did not run %s, because its own hooks are running.", hook, taskName)
+}
+
+func DependencySkippedRecursion(taskName string) string {
+	return fmt.Sprintf("%s cannot run as a dependency of one of its own hooks.", taskName)
+}
+
+func NotificationFailed(integration string, reason string) string {
+	return fmt.Sprintf("The %s notification could not be sent: %s", integration, reason)
+}
+
+func NotificationAttempts(reason string, attempts int) string {
+	if attempts == 1 {
+		return reason
+	}
+
+	return fmt.Sprintf("%s (after %d attempts)", reason, attempts)
+}
+
+func NotificationBlockedByGateway(at types.AccessType, str string) string {
+	return fmt.Sprintf("access to %s '%s' is not allowed by the domain settings", at.String(), str)
+}
+
+func NotificationUnknownChannel(channel string, channels []string) string {
+	return fmt.Sprintf("unknown channel '%s', expected one of: %s", channel, strings.Join(channels, ", "))
+}
+
+func ValidationUnknownRuleEvent(name string, names []string) string {
+	return fmt.Sprintf("unknown event '%s', expected one of: %s", name, strings.Join(names, ", "))
+}
+
+func ValidationMissingRuleDuration(name string) string {
+	return fmt.Sprintf("a duration is required for '%s' rules", name)
+}
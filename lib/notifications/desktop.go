@@ -5,17 +5,15 @@ import (
 )
 
 type DesktopNotification struct {
-    IconPath string
+	IconPath string
 }
 
 func NewDesktopNotification(iconPath string) *DesktopNotification {
 	return &DesktopNotification{
-        IconPath: iconPath,
-    }
+		IconPath: iconPath,
+	}
 }
 
 func (dn *DesktopNotification) Send(title, message string) error {
-    beeep.DefaultDuration = 8
-
-    return beeep.Notify(title, message, dn.IconPath)
+	return beeep.Notify(title, message, dn.IconPath)
 }
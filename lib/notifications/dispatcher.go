@@ -0,0 +1,205 @@
+package notifications
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/consts"
+)
+
+var (
+	ErrDuplicate = errors.New("the same notification was sent recently")
+	ErrQueueFull = errors.New("too many notifications are waiting to be sent")
+	ErrStopped   = errors.New("notifications are no longer being sent")
+)
+
+// DispatcherOptions configures how a Dispatcher delivers notifications.  Retries wait for `Backoff` before
+// the first retry, and twice as long before each retry after that.
+type DispatcherOptions struct {
+	QueueSize   int
+	RateLimit   time.Duration
+	Retries     int
+	Backoff     time.Duration
+	DedupWindow time.Duration
+}
+
+// DefaultDispatcherOptions returns the options that are used to send notifications for lifecycle events.
+func DefaultDispatcherOptions() DispatcherOptions {
+	return DispatcherOptions{
+		QueueSize:   consts.NOTIFICATION_QUEUE_SIZE,
+		RateLimit:   consts.NOTIFICATION_RATE_LIMIT_MS * time.Millisecond,
+		Retries:     consts.NOTIFICATION_RETRIES,
+		Backoff:     consts.NOTIFICATION_RETRY_BACKOFF_MS * time.Millisecond,
+		DedupWindow: consts.NOTIFICATION_DEDUP_WINDOW_SECONDS * time.Second,
+	}
+}
+
+// DeliveryHandler is called once a notification has been sent, or could not be sent after every attempt,
+// in which case `err` is the error of the last attempt.
+type DeliveryHandler func(channel string, attempts int, err error)
+
+type delivery struct {
+	sender  Sender
+	title   string
+	message string
+}
+
+// Dispatcher sends notifications in the background.  Each channel has its own queue, so a slow or failing
+// integration does not delay the notifications sent with other integrations.
+type Dispatcher struct {
+	options DispatcherOptions
+	handler DeliveryHandler
+	queues  map[string]chan delivery
+	recent  map[string]time.Time
+	pending sync.WaitGroup
+	abort   chan struct{}
+	stopped bool
+	lock    sync.Mutex
+}
+
+// NewDispatcher returns a dispatcher that calls `handler`, which may be nil, with the result of sending
+// each notification.
+func NewDispatcher(options DispatcherOptions, handler DeliveryHandler) *Dispatcher {
+	return &Dispatcher{
+		options: options,
+		handler: handler,
+		queues:  map[string]chan delivery{},
+		recent:  map[string]time.Time{},
+		abort:   make(chan struct{}),
+	}
+}
+
+// Dispatch queues the notification to be sent with `sender` on `channel`.  It returns ErrDuplicate without
+// queueing the notification if the same notification was dispatched on the channel within the dedup window,
+// and ErrQueueFull if too many notifications are already waiting to be sent on the channel.
+func (d *Dispatcher) Dispatch(channel string, sender Sender, title, message string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.stopped {
+		return ErrStopped
+	}
+
+	now := time.Now()
+	key := channel + "\x00" + title + "\x00" + message
+
+	for k, sentAt := range d.recent {
+		if now.Sub(sentAt) >= d.options.DedupWindow {
+			delete(d.recent, k)
+		}
+	}
+
+	if _, found := d.recent[key]; found {
+		return ErrDuplicate
+	}
+
+	queue, found := d.queues[channel]
+	if !found {
+		queue = make(chan delivery, d.options.QueueSize)
+		d.queues[channel] = queue
+
+		go d.run(channel, queue)
+	}
+
+	d.pending.Add(1)
+
+	select {
+	case queue <- delivery{sender: sender, title: title, message: message}:
+		d.recent[key] = now
+		return nil
+	default:
+		d.pending.Done()
+		return ErrQueueFull
+	}
+}
+
+// Stop stops accepting notifications, and waits for the queued notifications to be sent for up to
+// `timeout`.  It returns false if some of them were not sent in time; those that are still being retried
+// are not retried again.
+func (d *Dispatcher) Stop(timeout time.Duration) bool {
+	d.lock.Lock()
+	if !d.stopped {
+		d.stopped = true
+
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+	d.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		d.lock.Lock()
+		select {
+		case <-d.abort:
+		default:
+			close(d.abort)
+		}
+		d.lock.Unlock()
+
+		return false
+	}
+}
+
+// run sends the notifications queued for `channel` one at a time, waiting at least the rate limit between
+// them.
+func (d *Dispatcher) run(channel string, queue chan delivery) {
+	var lastSent time.Time
+
+	for item := range queue {
+		if !lastSent.IsZero() {
+			d.wait(d.options.RateLimit - time.Since(lastSent))
+		}
+
+		attempts, err := d.send(item)
+		lastSent = time.Now()
+
+		if d.handler != nil {
+			d.handler(channel, attempts, err)
+		}
+
+		d.pending.Done()
+	}
+}
+
+// send sends `item`, retrying with an increasing wait between attempts, and returns the number of attempts
+// made and the error of the last attempt.
+func (d *Dispatcher) send(item delivery) (int, error) {
+	backoff := d.options.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := item.sender.Send(item.title, item.message)
+		if err == nil || attempt > d.options.Retries || !d.wait(backoff) {
+			return attempt, err
+		}
+
+		backoff *= 2
+	}
+}
+
+// wait waits for `duration`, and returns false if the dispatcher stopped waiting for notifications to be
+// sent before then.
+func (d *Dispatcher) wait(duration time.Duration) bool {
+	if duration <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-d.abort:
+		return false
+	}
+}
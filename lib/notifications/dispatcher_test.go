@@ -0,0 +1,159 @@
+package notifications_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/notifications"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSender fails the first `failures` times it is called, and records when each notification was sent.
+type fakeSender struct {
+	lock     sync.Mutex
+	failures int
+	calls    int
+	sent     []string
+	times    []time.Time
+	block    chan struct{}
+	started  chan struct{}
+}
+
+func (s *fakeSender) Send(title, message string) error {
+	if s.started != nil {
+		s.started <- struct{}{}
+	}
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("service unavailable")
+	}
+
+	s.sent = append(s.sent, title+": "+message)
+	s.times = append(s.times, time.Now())
+
+	return nil
+}
+
+// deliveryRecorder records the results that a dispatcher reports.
+type deliveryRecorder struct {
+	lock     sync.Mutex
+	attempts []int
+	errs     []error
+}
+
+func (r *deliveryRecorder) handle(channel string, attempts int, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.attempts = append(r.attempts, attempts)
+	r.errs = append(r.errs, err)
+}
+
+func testOptions() notifications.DispatcherOptions {
+	return notifications.DispatcherOptions{QueueSize: 10, Retries: 2, Backoff: 10 * time.Millisecond, DedupWindow: time.Minute}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	recorder := &deliveryRecorder{}
+	d := notifications.NewDispatcher(testOptions(), recorder.handle)
+
+	flaky := &fakeSender{failures: 2}
+	failing := &fakeSender{failures: 100}
+
+	assert.NoError(t, d.Dispatch("slack", flaky, "deploy", "finished"))
+	assert.NoError(t, d.Dispatch("webhook", failing, "deploy", "finished"))
+	assert.True(t, d.Stop(5*time.Second))
+
+	assert.Equal(t, []string{"deploy: finished"}, flaky.sent)
+	assert.Equal(t, 3, failing.calls)
+	assert.ElementsMatch(t, []int{3, 3}, recorder.attempts)
+	assert.Contains(t, recorder.errs, nil)
+	assert.Contains(t, recorder.errs, errors.New("service unavailable"))
+}
+
+func TestDispatcherSkipsDuplicatesWithinWindow(t *testing.T) {
+	options := testOptions()
+	options.DedupWindow = 100 * time.Millisecond
+
+	d := notifications.NewDispatcher(options, nil)
+	sender := &fakeSender{}
+
+	assert.NoError(t, d.Dispatch("slack", sender, "deploy", "failed"))
+	assert.ErrorIs(t, d.Dispatch("slack", sender, "deploy", "failed"), notifications.ErrDuplicate)
+	assert.NoError(t, d.Dispatch("slack", sender, "deploy", "finished"))
+	assert.NoError(t, d.Dispatch("email", sender, "deploy", "failed"))
+
+	time.Sleep(150 * time.Millisecond)
+	assert.NoError(t, d.Dispatch("slack", sender, "deploy", "failed"))
+
+	assert.True(t, d.Stop(5*time.Second))
+	assert.Len(t, sender.sent, 4)
+	assert.ErrorIs(t, d.Dispatch("slack", sender, "deploy", "stopped"), notifications.ErrStopped)
+}
+
+func TestDispatcherRateLimitsEachChannel(t *testing.T) {
+	options := testOptions()
+	options.RateLimit = 100 * time.Millisecond
+
+	d := notifications.NewDispatcher(options, nil)
+	slack, email := &fakeSender{}, &fakeSender{}
+
+	start := time.Now()
+	for _, message := range []string{"one", "two", "three"} {
+		assert.NoError(t, d.Dispatch("slack", slack, "deploy", message))
+		assert.NoError(t, d.Dispatch("email", email, "deploy", message))
+	}
+	assert.True(t, d.Stop(5*time.Second))
+
+	assert.Equal(t, []string{"deploy: one", "deploy: two", "deploy: three"}, slack.sent)
+	assert.GreaterOrEqual(t, slack.times[1].Sub(slack.times[0]), 90*time.Millisecond)
+	assert.GreaterOrEqual(t, slack.times[2].Sub(slack.times[1]), 90*time.Millisecond)
+
+	// the channels are rate limited separately, so both finish at about the same time
+	assert.Less(t, email.times[2].Sub(start), 400*time.Millisecond)
+}
+
+func TestDispatcherReportsFullQueues(t *testing.T) {
+	options := testOptions()
+	options.QueueSize = 1
+
+	d := notifications.NewDispatcher(options, nil)
+	sender := &fakeSender{block: make(chan struct{}), started: make(chan struct{}, 10)}
+
+	assert.NoError(t, d.Dispatch("slack", sender, "deploy", "one"))
+	<-sender.started
+
+	assert.NoError(t, d.Dispatch("slack", sender, "deploy", "two"))
+	assert.ErrorIs(t, d.Dispatch("slack", sender, "deploy", "three"), notifications.ErrQueueFull)
+
+	close(sender.block)
+	assert.True(t, d.Stop(5*time.Second))
+	assert.Equal(t, []string{"deploy: one", "deploy: two"}, sender.sent)
+}
+
+func TestDispatcherStopsWaitingAfterTimeout(t *testing.T) {
+	options := testOptions()
+	options.Retries, options.Backoff = 5, time.Minute
+
+	recorder := &deliveryRecorder{}
+	d := notifications.NewDispatcher(options, recorder.handle)
+
+	assert.NoError(t, d.Dispatch("slack", &fakeSender{failures: 100}, "deploy", "finished"))
+	assert.False(t, d.Stop(50*time.Millisecond))
+
+	// the retry that was waiting is abandoned
+	assert.Eventually(t, func() bool {
+		recorder.lock.Lock()
+		defer recorder.lock.Unlock()
+		return len(recorder.attempts) == 1 && recorder.attempts[0] == 1
+	}, time.Second, 10*time.Millisecond)
+}
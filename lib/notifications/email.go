@@ -0,0 +1,157 @@
+package notifications
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/settings"
+	"github.com/stackup-app/stackup/lib/types"
+)
+
+// DefaultSmtpPort is the port used to connect to SMTP servers if none is configured, which is the port for
+// submitting messages with STARTTLS.
+const DefaultSmtpPort = 587
+
+// EmailNotification sends notifications as plain text emails through an SMTP server.  STARTTLS is used when
+// the server supports it, and is required if `StartTls` is set.  The server is only authenticated with if a
+// username is configured.
+type EmailNotification struct {
+	Host      string
+	Port      int
+	Username  string
+	Password  string
+	From      string
+	To        []string
+	StartTls  bool
+	TlsConfig *tls.Config
+	Gateway   types.GatewayContract
+	Timeout   time.Duration
+}
+
+// NewEmailNotification creates an EmailNotification that sends from `from` to `to` through the SMTP server
+// at `host` and `port`.
+func NewEmailNotification(host string, port int, from string, to ...string) *EmailNotification {
+	if port == 0 {
+		port = DefaultSmtpPort
+	}
+
+	return &EmailNotification{
+		Host:    os.ExpandEnv(host),
+		Port:    port,
+		From:    os.ExpandEnv(from),
+		To:      to,
+		Timeout: 30 * time.Second,
+	}
+}
+
+// NewEmailNotificationFromSettings creates an EmailNotification using the server and credentials in
+// `config`, which sends to `to`.  The server's hostname is checked against the domain settings by `gateway`
+// before each notification is sent; `gateway` may be nil to skip the check.
+func NewEmailNotificationFromSettings(config settings.WorkflowSettingsNotificationsEmail, gateway types.GatewayContract, to ...string) *EmailNotification {
+	result := NewEmailNotification(config.Host, config.Port, config.From, to...)
+	result.Username = os.ExpandEnv(config.Username)
+	result.Password = os.ExpandEnv(config.Password)
+	result.StartTls = config.StartTls
+	result.Gateway = gateway
+
+	return result
+}
+
+// Send sends an email with `title` as its subject and `message` as its body to each recipient.
+func (en *EmailNotification) Send(title, message string) error {
+	if en.Host == "" || en.From == "" || len(en.To) == 0 {
+		return errors.New("an smtp host, a sender and at least one recipient are required")
+	}
+
+	if en.Gateway != nil && !en.Gateway.Allowed(en.Host) {
+		return errors.New(messages.NotificationBlockedByGateway(types.AccessTypeDomain, en.Host))
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(en.Host, strconv.Itoa(en.Port)), en.Timeout)
+	if err != nil {
+		return err
+	}
+
+	conn.SetDeadline(time.Now().Add(en.Timeout))
+
+	client, err := smtp.NewClient(conn, en.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if supported, _ := client.Extension("STARTTLS"); supported {
+		config := &tls.Config{}
+		if en.TlsConfig != nil {
+			config = en.TlsConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = en.Host
+		}
+
+		if err := client.StartTLS(config); err != nil {
+			return err
+		}
+	} else if en.StartTls {
+		return errors.New("the smtp server does not support STARTTLS")
+	}
+
+	if en.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", en.Username, en.Password, en.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(en.From); err != nil {
+		return err
+	}
+
+	for _, to := range en.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("failed to send email to '%s': %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(en.buildMessage(title, message)); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// buildMessage returns the headers and body of the email, with CRLF line endings.
+func (en *EmailNotification) buildMessage(title, message string) []byte {
+	var sb strings.Builder
+
+	sb.WriteString("From: " + en.From + "\r\n")
+	sb.WriteString("To: " + strings.Join(en.To, ", ") + "\r\n")
+	sb.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", title) + "\r\n")
+	sb.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	sb.WriteString("\r\n")
+
+	body := strings.ReplaceAll(strings.ReplaceAll(message, "\r\n", "\n"), "\n", "\r\n")
+	sb.WriteString(body + "\r\n")
+
+	return []byte(sb.String())
+}
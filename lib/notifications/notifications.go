@@ -0,0 +1,6 @@
+package notifications
+
+// Sender sends a notification with a title and a message.
+type Sender interface {
+	Send(title, message string) error
+}
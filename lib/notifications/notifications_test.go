@@ -0,0 +1,311 @@
+package notifications_test
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stackup-app/stackup/lib/notifications"
+	"github.com/stackup-app/stackup/lib/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingServer is a stand-in for the Telegram and Slack APIs that records each request's path and body.
+type recordingServer struct {
+	*httptest.Server
+	lock     sync.Mutex
+	paths    []string
+	bodies   []map[string]any
+	response string
+}
+
+func newRecordingServer(t *testing.T, response string) *recordingServer {
+	s := &recordingServer{response: response}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+
+		s.lock.Lock()
+		s.paths = append(s.paths, r.URL.Path)
+		s.bodies = append(s.bodies, body)
+		s.lock.Unlock()
+
+		w.Write([]byte(s.response))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestTelegramNotificationSendsToEachChat(t *testing.T) {
+	server := newRecordingServer(t, `{"ok":true}`)
+
+	tn := notifications.NewTelegramNotification("token123", 1001, 1002)
+	tn.ApiUrl = server.URL
+
+	assert.NoError(t, tn.Send("deploy", "finished"))
+	assert.Equal(t, []string{"/bottoken123/sendMessage", "/bottoken123/sendMessage"}, server.paths)
+	assert.Equal(t, []map[string]any{
+		{"chat_id": float64(1001), "text": "deploy\nfinished"},
+		{"chat_id": float64(1002), "text": "deploy\nfinished"},
+	}, server.bodies)
+}
+
+func TestTelegramNotificationReportsApiErrors(t *testing.T) {
+	server := newRecordingServer(t, `{"ok":false,"description":"Bad Request: chat not found"}`)
+
+	tn := notifications.NewTelegramNotification("token123", 1001)
+	tn.ApiUrl = server.URL
+
+	err := tn.Send("deploy", "finished")
+	assert.ErrorContains(t, err, "chat not found")
+	assert.NotContains(t, err.Error(), "token123")
+}
+
+func TestSlackNotificationSendsToEachChannel(t *testing.T) {
+	server := newRecordingServer(t, "ok")
+
+	sn := notifications.NewSlackNotification(server.URL+"/hooks/abc", "#dev", "#ops")
+
+	assert.NoError(t, sn.Send("deploy", "finished"))
+	assert.Equal(t, []string{"/hooks/abc", "/hooks/abc"}, server.paths)
+	assert.Equal(t, "#dev", server.bodies[0]["channel"])
+	assert.Equal(t, "#ops", server.bodies[1]["channel"])
+	assert.Equal(t, "finished", server.bodies[1]["text"])
+}
+
+// deniedGateway is a stand-in for the http gateway that does not allow any urls or domains.
+type deniedGateway struct{}
+
+func (deniedGateway) Allowed(url string) bool                              { return false }
+func (deniedGateway) SaveUrlToFile(url string, filename string) error      { return nil }
+func (deniedGateway) GetUrl(url string, headers ...string) (string, error) { return "", nil }
+
+func TestWebhookNotificationSendsBodyTemplate(t *testing.T) {
+	var method, auth, contentType string
+	var body map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, auth, contentType = r.Method, r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer server.Close()
+
+	wn := notifications.NewWebhookNotificationFromSettings(settings.WorkflowSettingsNotificationsWebhook{
+		Url:     server.URL + "/hooks/abc",
+		Method:  "put",
+		Headers: []string{"Authorization: Bearer abc123"},
+		Body:    `{"content": "**{title}**: {message}", "username": "stackup"}`,
+	}, nil)
+
+	assert.NoError(t, wn.Send("deploy", `"api" finished`+"\nin 3s"))
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "Bearer abc123", auth)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, map[string]any{"content": "**deploy**: \"api\" finished\nin 3s", "username": "stackup"}, body)
+}
+
+func TestWebhookNotificationReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	wn := notifications.NewWebhookNotification(server.URL)
+	assert.ErrorContains(t, wn.Send("deploy", "finished"), "400")
+
+	wn.Body = `{"text": {message}}`
+	assert.ErrorContains(t, wn.Send("deploy", "finished"), "not valid JSON")
+
+	wn.Gateway = deniedGateway{}
+	assert.ErrorContains(t, wn.Send("deploy", "finished"), "not allowed")
+}
+
+// smtpServer is a stand-in for an SMTP server that supports STARTTLS and AUTH PLAIN, and records the
+// commands and messages it receives.
+type smtpServer struct {
+	addr     string
+	tls      *tls.Config
+	lock     sync.Mutex
+	commands []string
+	messages []string
+}
+
+func newSmtpServer(t *testing.T, tlsConfig *tls.Config) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	s := &smtpServer{addr: listener.Addr().String(), tls: tlsConfig}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 localhost ESMTP")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		s.lock.Lock()
+		s.commands = append(s.commands, line)
+		s.lock.Unlock()
+
+		switch command {
+		case "EHLO":
+			reply("250-localhost")
+			if s.tls != nil {
+				if _, isTls := conn.(*tls.Conn); !isTls {
+					reply("250-STARTTLS")
+				}
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready")
+			conn = tls.Server(conn, s.tls)
+			reader = bufio.NewReader(conn)
+		case "DATA":
+			reply("354 go ahead")
+
+			var message strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+				message.WriteString(line)
+			}
+
+			s.lock.Lock()
+			s.messages = append(s.messages, message.String())
+			s.lock.Unlock()
+
+			reply("250 queued")
+		case "AUTH":
+			reply("235 authenticated")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestEmailNotificationSendsWithStartTlsAndAuth(t *testing.T) {
+	// the test server's certificate is used for the smtp server as well
+	httpsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer httpsServer.Close()
+
+	server := newSmtpServer(t, httpsServer.TLS)
+	host, port, _ := net.SplitHostPort(server.addr)
+	portNumber, _ := strconv.Atoi(port)
+
+	en := notifications.NewEmailNotificationFromSettings(settings.WorkflowSettingsNotificationsEmail{
+		Host:     host,
+		Port:     portNumber,
+		Username: "stackup",
+		Password: "secret",
+		From:     "stackup@example.com",
+		StartTls: true,
+	}, nil, "dev@example.com", "ops@example.com")
+	en.TlsConfig = &tls.Config{RootCAs: httpsServer.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+
+	assert.NoError(t, en.Send("deploy", "finished\nin 3s"))
+
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	assert.Contains(t, server.commands, "STARTTLS")
+	assert.Contains(t, server.commands, "AUTH PLAIN "+base64.StdEncoding.EncodeToString([]byte("\x00stackup\x00secret")))
+	assert.Contains(t, server.commands, "MAIL FROM:<stackup@example.com>")
+	assert.Contains(t, server.commands, "RCPT TO:<ops@example.com>")
+	if assert.Len(t, server.messages, 1) {
+		assert.Contains(t, server.messages[0], "To: dev@example.com, ops@example.com\r\n")
+		assert.Contains(t, server.messages[0], "Subject: deploy\r\n")
+		assert.Contains(t, server.messages[0], "\r\n\r\nfinished\r\nin 3s\r\n")
+	}
+}
+
+func TestEmailNotificationRequiresStartTls(t *testing.T) {
+	server := newSmtpServer(t, nil)
+	host, port, _ := net.SplitHostPort(server.addr)
+	portNumber, _ := strconv.Atoi(port)
+
+	en := notifications.NewEmailNotification(host, portNumber, "stackup@example.com", "dev@example.com")
+	en.StartTls = true
+
+	assert.ErrorContains(t, en.Send("deploy", "finished"), "STARTTLS")
+
+	en.Gateway = deniedGateway{}
+	assert.ErrorContains(t, en.Send("deploy", "finished"), "not allowed")
+
+	// without the STARTTLS requirement, the message is sent over the unencrypted connection
+	en.StartTls, en.Gateway = false, nil
+	assert.NoError(t, en.Send("deploy", "finished"))
+
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	assert.Len(t, server.messages, 1)
+}
+
+func TestTelegramNotificationDoesNotAccumulateReceivers(t *testing.T) {
+	server := newRecordingServer(t, `{"ok":true}`)
+
+	tn := notifications.NewTelegramNotification("token123", 1001)
+	tn.ApiUrl = server.URL
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, tn.Send("deploy", "finished"))
+	}
+
+	// each send is delivered to the chat once
+	assert.Len(t, server.paths, 3)
+}
+
+func TestSlackNotificationReportsWebhookErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no_service"))
+	}))
+	defer server.Close()
+
+	sn := notifications.NewSlackNotification(server.URL+"/hooks/abc", "#dev", "#ops")
+
+	err := sn.Send("deploy", "finished")
+	assert.ErrorContains(t, err, "#dev")
+	assert.ErrorContains(t, err, "404")
+}
@@ -1,28 +1,34 @@
 package notifications
 
 import (
+	"fmt"
+
 	slackapi "github.com/slack-go/slack"
 )
 
 type SlackNotification struct {
 	WebhookUrl string
-	ChannelIds  []string
+	ChannelIds []string
 }
 
-// NewSlackNotification creates a new instance of the TelegramNotification struct with the provided notifier,
-// API token, and chat IDs.
+// NewSlackNotification creates a new instance of the SlackNotification struct with the provided webhook url
+// and channel IDs.
 func NewSlackNotification(webhookUrl string, channelIds ...string) *SlackNotification {
 	return &SlackNotification{
 		WebhookUrl: webhookUrl,
-		ChannelIds:  channelIds,
+		ChannelIds: channelIds,
 	}
 }
 
+// Send sends the message to each channel, stopping at the first channel that the message could not be sent to.
 func (tn *SlackNotification) Send(title, message string) error {
-    for _, channelId := range tn.ChannelIds {
-        msg := slackapi.WebhookMessage{Channel: channelId, Text: message}
-        slackapi.PostWebhook(tn.WebhookUrl, &msg)
-    }
+	for _, channelId := range tn.ChannelIds {
+		msg := slackapi.WebhookMessage{Channel: channelId, Text: message}
+
+		if err := slackapi.PostWebhook(tn.WebhookUrl, &msg); err != nil {
+			return fmt.Errorf("failed to send message to Slack channel '%s': %w", channelId, err)
+		}
+	}
 
 	return nil
 }
@@ -1,41 +1,113 @@
 package notifications
 
 import (
-	"context"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/nikoksr/notify"
-	"github.com/nikoksr/notify/service/telegram"
+	"github.com/stackup-app/stackup/lib/settings"
 )
 
+// TelegramApiUrl is the url of the Telegram Bot API, which is used unless another url is configured, such
+// as the url of a local Bot API server.
+const TelegramApiUrl = "https://api.telegram.org"
+
 type TelegramNotification struct {
 	ApiToken string
+	ApiUrl   string
 	ChatIds  []int64
-	Service  *telegram.Telegram
-	Notifier *notify.Notify
+	Client   *http.Client
 }
 
-// NewTelegramNotification creates a new instance of the TelegramNotification struct with the provided notifier,
-// API token, and chat IDs.
-func NewTelegramNotification(apiToken string, chatIds ...int64) *TelegramNotification {
-	service, _ := telegram.New(os.ExpandEnv(apiToken))
+type telegramResponse struct {
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
 
+// NewTelegramNotification creates a new instance of the TelegramNotification struct with the provided
+// API token and chat IDs.
+func NewTelegramNotification(apiToken string, chatIds ...int64) *TelegramNotification {
 	return &TelegramNotification{
-		ApiToken: apiToken,
+		ApiToken: os.ExpandEnv(apiToken),
+		ApiUrl:   TelegramApiUrl,
 		ChatIds:  chatIds,
-		Service:  service,
+		Client:   &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
+// NewTelegramNotificationFromSettings creates a TelegramNotification that uses the api token and url in
+// `config`, and sends to `chatIds`.
+func NewTelegramNotificationFromSettings(config settings.WorkflowSettingsNotificationsTelegram, chatIds ...int64) *TelegramNotification {
+	result := NewTelegramNotification(config.APIKey, chatIds...)
+
+	if config.ApiUrl != "" {
+		result.ApiUrl = config.ApiUrl
+	}
+
+	return result
+}
+
+// ParseChatIds converts chat ids, which are configured and passed to scripts as strings, to numbers.
+func ParseChatIds(chatIds []string) ([]int64, error) {
+	result := []int64{}
+
+	for _, chatId := range chatIds {
+		id, err := strconv.ParseInt(strings.TrimSpace(chatId), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat id '%s'", chatId)
+		}
+
+		result = append(result, id)
+	}
+
+	return result, nil
+}
+
+// Send sends the title and message to each chat, stopping at the first chat that the message could not be
+// sent to.
 func (tn *TelegramNotification) Send(title, message string) error {
-	tn.Service.AddReceivers(tn.ChatIds...)
-	notify.UseServices(tn.Service)
+	for _, chatId := range tn.ChatIds {
+		if err := tn.sendMessage(chatId, title+"\n"+message); err != nil {
+			return fmt.Errorf("failed to send message to Telegram chat '%d': %w", chatId, err)
+		}
+	}
+
+	return nil
+}
+
+func (tn *TelegramNotification) sendMessage(chatId int64, text string) error {
+	body, _ := json.Marshal(map[string]any{"chat_id": chatId, "text": text})
+	url := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimSuffix(tn.ApiUrl, "/"), tn.ApiToken)
 
-	err := notify.Send(
-		context.Background(),
-		title,
-		message,
-	)
+	resp, err := tn.Client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// the url contains the api token, so it is not included in the error
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result telegramResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	if resp.StatusCode != http.StatusOK || !result.Ok {
+		if result.Description != "" {
+			return errors.New(result.Description)
+		}
+
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
 
-	return err
+	return nil
 }
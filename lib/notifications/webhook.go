@@ -0,0 +1,116 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/settings"
+	"github.com/stackup-app/stackup/lib/types"
+)
+
+// DefaultWebhookBody is the body that is sent to webhooks that do not configure one, which is understood by
+// Mattermost and other Slack-compatible webhooks.
+const DefaultWebhookBody = `{"text": "{title}\n{message}"}`
+
+// WebhookNotification sends notifications to any webhook that accepts a JSON body, such as Mattermost or
+// Discord webhooks.  The `{title}` and `{message}` placeholders in `Body` are replaced with the title and
+// message, escaped so that they can be used inside of JSON strings.
+type WebhookNotification struct {
+	Url     string
+	Method  string
+	Headers []string
+	Body    string
+	Gateway types.GatewayContract
+	Client  *http.Client
+}
+
+// NewWebhookNotification creates a WebhookNotification that posts the default body to `url`.
+func NewWebhookNotification(url string) *WebhookNotification {
+	return &WebhookNotification{
+		Url:    os.ExpandEnv(url),
+		Method: http.MethodPost,
+		Body:   DefaultWebhookBody,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewWebhookNotificationFromSettings creates a WebhookNotification using the url, method, headers and body
+// in `config`.  The url is checked against the domain settings by `gateway` before each notification is
+// sent; `gateway` may be nil to skip the check.
+func NewWebhookNotificationFromSettings(config settings.WorkflowSettingsNotificationsWebhook, gateway types.GatewayContract) *WebhookNotification {
+	result := NewWebhookNotification(config.Url)
+	result.Gateway = gateway
+
+	if config.Method != "" {
+		result.Method = strings.ToUpper(config.Method)
+	}
+	if config.Body != "" {
+		result.Body = config.Body
+	}
+
+	for _, header := range config.Headers {
+		result.Headers = append(result.Headers, os.ExpandEnv(header))
+	}
+
+	return result
+}
+
+// Send sends the body to the webhook, and returns an error if it does not respond with a successful status.
+func (wn *WebhookNotification) Send(title, message string) error {
+	if wn.Url == "" {
+		return errors.New("no webhook url has been configured")
+	}
+
+	if wn.Gateway != nil && !wn.Gateway.Allowed(wn.Url) {
+		return errors.New(messages.NotificationBlockedByGateway(types.AccessTypeUrl, wn.Url))
+	}
+
+	body := formatWebhookBody(wn.Body, title, message)
+	if !json.Valid([]byte(body)) {
+		return errors.New("the webhook body is not valid JSON")
+	}
+
+	req, err := http.NewRequest(wn.Method, wn.Url, bytes.NewReader([]byte(body)))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for _, header := range wn.Headers {
+		if name, value, found := strings.Cut(header, ":"); found {
+			req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+	}
+
+	resp, err := wn.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// formatWebhookBody replaces the placeholders in `body` with `title` and `message`, escaped for use in
+// JSON strings.
+func formatWebhookBody(body string, title string, message string) string {
+	return strings.NewReplacer("{title}", jsonEscape(title), "{message}", jsonEscape(message)).Replace(body)
+}
+
+func jsonEscape(s string) string {
+	encoded, _ := json.Marshal(s)
+
+	return string(encoded[1 : len(encoded)-1])
+}
@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/stackup-app/stackup/lib/utils"
+)
+
+const DRAFT_07 = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema document, or a schema within one.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+type generator struct {
+	definitions map[string]*Schema
+}
+
+// Generate returns a JSON Schema for yaml documents that are decoded into a value of type `t`.  Structs are
+// added to the schema's definitions, and only their fields that have a `yaml` tag are included.
+// The root schema refers to its type through `allOf`, since draft-07 ignores the keywords next to a `$ref`.
+func Generate(t reflect.Type, title string) *Schema {
+	g := &generator{definitions: map[string]*Schema{}}
+
+	return &Schema{
+		Schema:      DRAFT_07,
+		Title:       title,
+		AllOf:       []*Schema{g.schemaFor(t)},
+		Definitions: g.definitions,
+	}
+}
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+
+	return &Schema{}
+}
+
+// structSchema adds the schema for the struct `t` to the definitions, if it hasn't been added already, and
+// returns a reference to it.
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	ref := &Schema{Ref: "#/definitions/" + t.Name()}
+
+	if _, found := g.definitions[t.Name()]; found {
+		return ref
+	}
+
+	// add the definition before generating the fields so that recursive types refer to it
+	result := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	g.definitions[t.Name()] = result
+
+	fields := utils.YamlFields(t)
+	names := make([]string, 0, len(fields))
+
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		result.Properties[name] = g.schemaFor(fields[name].Type)
+	}
+
+	return ref
+}
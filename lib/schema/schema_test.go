@@ -0,0 +1,44 @@
+package schema_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stackup-app/stackup/lib/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEmbedded struct {
+	Allowed []string `yaml:"allowed"`
+}
+
+type testNode struct {
+	Name         string            `yaml:"name"`
+	Children     []*testNode       `yaml:"children,omitempty"`
+	Labels       map[string]string `yaml:"labels"`
+	Count        int               `yaml:"count"`
+	Enabled      bool              `yaml:"enabled"`
+	Ignored      string
+	testEmbedded `yaml:",inline"`
+}
+
+func TestGenerate(t *testing.T) {
+	s := schema.Generate(reflect.TypeOf(testNode{}), "Test")
+
+	assert.Equal(t, schema.DRAFT_07, s.Schema)
+	assert.Equal(t, "Test", s.Title)
+	assert.Empty(t, s.Ref)
+	assert.Equal(t, []*schema.Schema{{Ref: "#/definitions/testNode"}}, s.AllOf)
+
+	node := s.Definitions["testNode"]
+	assert.Equal(t, "object", node.Type)
+	assert.Equal(t, false, node.AdditionalProperties)
+	assert.Len(t, node.Properties, 6)
+	assert.Equal(t, "string", node.Properties["name"].Type)
+	assert.Equal(t, "integer", node.Properties["count"].Type)
+	assert.Equal(t, "boolean", node.Properties["enabled"].Type)
+	assert.Equal(t, "#/definitions/testNode", node.Properties["children"].Items.Ref)
+	assert.Equal(t, &schema.Schema{Type: "string"}, node.Properties["labels"].AdditionalProperties)
+	assert.Equal(t, "string", node.Properties["allowed"].Items.Type)
+	assert.NotContains(t, node.Properties, "ignored")
+}
@@ -5,11 +5,14 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/support"
 	"github.com/stackup-app/stackup/lib/types"
 	"github.com/stackup-app/stackup/lib/utils"
 )
 
 type ScriptFs struct {
+	engine types.JavaScriptEngineContract
 	types.ScriptExtensionContract
 }
 
@@ -22,9 +25,21 @@ func (fs *ScriptFs) GetName() string {
 }
 
 func (ex *ScriptFs) OnInstall(engine types.JavaScriptEngineContract) {
+	ex.engine = engine
 	engine.GetVm().Set(ex.GetName(), ex)
 }
 
+// isDryRun returns true if files should not be written, after reporting that `action` was skipped.
+func (fs *ScriptFs) isDryRun(action string) bool {
+	if fs.engine == nil || !fs.engine.IsDryRun() {
+		return false
+	}
+
+	support.SkippedMessageWithSymbol(messages.DryRunScriptSkipped(action))
+
+	return true
+}
+
 func (fs *ScriptFs) ReadFile(filename string) (string, error) {
 	content, err := os.ReadFile(filename)
 	if err != nil {
@@ -35,6 +50,10 @@ func (fs *ScriptFs) ReadFile(filename string) (string, error) {
 }
 
 func (fs *ScriptFs) WriteFile(filename string, content string) error {
+	if fs.isDryRun("fs.WriteFile('" + filename + "')") {
+		return nil
+	}
+
 	err := os.WriteFile(filename, []byte(content), 0644)
 
 	return err
@@ -59,6 +78,10 @@ func (fs *ScriptFs) ReadJSON(filename string) (interface{}, error) {
 }
 
 func (fs *ScriptFs) WriteJSON(filename string, data interface{}) error {
+	if fs.isDryRun("fs.WriteJSON('" + filename + "')") {
+		return nil
+	}
+
 	_, err := os.Stat(filename)
 	if os.IsNotExist(err) {
 		return err
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/robertkrimen/otto"
+	"github.com/stackup-app/stackup/lib/messages"
 	devextension "github.com/stackup-app/stackup/lib/scripting/extensions/dev_extension"
 	"github.com/stackup-app/stackup/lib/semver"
 	"github.com/stackup-app/stackup/lib/support"
@@ -64,6 +65,15 @@ func getResult(call otto.FunctionCall, v any) otto.Value {
 	return result
 }
 
+// returns the string value of the argument at `index`, or an empty string if it was not provided.
+func getOptionalString(call otto.FunctionCall, index int) string {
+	if call.Argument(index).IsUndefined() {
+		return ""
+	}
+
+	return call.Argument(index).String()
+}
+
 func (jsf *JavaScriptFunctions) createSetTimeoutFunction(call otto.FunctionCall) otto.Value {
 	// Get the callback function and delay time from the arguments
 	callback := call.Argument(0)
@@ -87,6 +97,11 @@ func (jsf *JavaScriptFunctions) createSetTimeoutFunction(call otto.FunctionCall)
 }
 
 func (jsf *JavaScriptFunctions) createFetchFunction(call otto.FunctionCall) otto.Value {
+	if jsf.Engine.IsDryRun() {
+		support.SkippedMessageWithSymbol(messages.DryRunScriptSkipped("fetch('" + call.Argument(0).String() + "')"))
+		return getResult(call, "")
+	}
+
 	result, _ := jsf.Engine.GetGateway().GetUrl(call.Argument(0).String())
 
 	return getResult(call, result)
@@ -94,6 +109,12 @@ func (jsf *JavaScriptFunctions) createFetchFunction(call otto.FunctionCall) otto
 
 func (jsf *JavaScriptFunctions) createFetchJsonFunction(call otto.FunctionCall) otto.Value {
 	var result interface{}
+
+	if jsf.Engine.IsDryRun() {
+		support.SkippedMessageWithSymbol(messages.DryRunScriptSkipped("fetchJson('" + call.Argument(0).String() + "')"))
+		return getResult(call, result)
+	}
+
 	gw := jsf.Engine.GetGateway()
 	utils.GetUrlJson(call.Argument(0).String(), &result, &gw)
 
@@ -125,7 +146,12 @@ func (jsf *JavaScriptFunctions) createSemverFunction(call otto.FunctionCall) ott
 }
 
 func (jsf *JavaScriptFunctions) createOutputOfFunction(call otto.FunctionCall) otto.Value {
-	result := support.GetCommandOutput(call.Argument(0).String())
+	if jsf.Engine.IsDryRun() {
+		support.SkippedMessageWithSymbol(messages.DryRunCommandSkipped(call.Argument(0).String()))
+		return getResult(call, "")
+	}
+
+	result := support.GetCommandOutput(call.Argument(0).String(), getOptionalString(call, 1))
 
 	return getResult(call, result)
 }
@@ -240,7 +266,15 @@ func (jsf *JavaScriptFunctions) createJavascriptFunctionEnv(call otto.FunctionCa
 }
 
 func (jsf *JavaScriptFunctions) createJavascriptFunctionExec(call otto.FunctionCall) otto.Value {
-	result, err := utils.RunCommandInPath(call.Argument(0).String(), ".", false)
+	if jsf.Engine.IsDryRun() {
+		support.SkippedMessageWithSymbol(messages.DryRunCommandSkipped(call.Argument(0).String()))
+		return getResult(call, "")
+	}
+
+	result, err := utils.RunCommand(call.Argument(0).String(), utils.CommandOptions{
+		Cwd:   ".",
+		Shell: getOptionalString(call, 1),
+	})
 
 	if err != nil {
 		support.WarningMessage(err.Error())
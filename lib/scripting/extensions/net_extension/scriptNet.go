@@ -1,6 +1,7 @@
 package netextension
 
 import (
+	"github.com/stackup-app/stackup/lib/messages"
 	"github.com/stackup-app/stackup/lib/support"
 	"github.com/stackup-app/stackup/lib/types"
 	"github.com/stackup-app/stackup/lib/utils"
@@ -8,6 +9,7 @@ import (
 
 type ScriptNet struct {
 	gateway types.GatewayContract
+	engine  types.JavaScriptEngineContract
 }
 
 func Create(gw types.GatewayContract) *ScriptNet {
@@ -21,14 +23,30 @@ func (net *ScriptNet) GetName() string {
 }
 
 func (ex *ScriptNet) OnInstall(engine types.JavaScriptEngineContract) {
+	ex.engine = engine
 	engine.GetVm().Set(ex.GetName(), ex)
 }
 
+// isDryRun returns true if no requests should be made, after reporting that `action` was skipped.
+func (net *ScriptNet) isDryRun(action string) bool {
+	if net.engine == nil || !net.engine.IsDryRun() {
+		return false
+	}
+
+	support.SkippedMessageWithSymbol(messages.DryRunScriptSkipped(action))
+
+	return true
+}
+
 func (net *ScriptNet) gatewayPtr() *types.GatewayContract {
 	return &net.gateway
 }
 
 func (net *ScriptNet) Fetch(url string) any {
+	if net.isDryRun("net.Fetch('" + url + "')") {
+		return ""
+	}
+
 	// if !net.gateway.Allowed(url) {
 	// 	support.FailureMessageWithXMark(" [script] fetch failed: access to " + url + " is not allowed.")
 	// 	return ""
@@ -47,6 +65,10 @@ func (net *ScriptNet) Fetch(url string) any {
 func (net *ScriptNet) FetchJson(url string) any {
 	var result interface{} = nil
 
+	if net.isDryRun("net.FetchJson('" + url + "')") {
+		return result
+	}
+
 	if !net.gateway.Allowed(url) {
 		support.FailureMessageWithXMark(" [script] fetchJson failed: access to " + url + " is not allowed.")
 		return result
@@ -58,6 +80,10 @@ func (net *ScriptNet) FetchJson(url string) any {
 }
 
 func (net *ScriptNet) DownloadTo(url string, filename string) {
+	if net.isDryRun("net.DownloadTo('" + url + "')") {
+		return
+	}
+
 	if !net.gateway.Allowed(url) {
 		support.FailureMessageWithXMark(" [script] DownloadTo() failed: access to '" + url + "' is not allowed.")
 		return
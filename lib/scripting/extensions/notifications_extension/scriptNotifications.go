@@ -0,0 +1,203 @@
+package notificationsextension
+
+import (
+	"github.com/stackup-app/stackup/lib/messages"
+	"github.com/stackup-app/stackup/lib/notifications"
+	"github.com/stackup-app/stackup/lib/settings"
+	"github.com/stackup-app/stackup/lib/support"
+	"github.com/stackup-app/stackup/lib/types"
+)
+
+const name = "notifications"
+
+// the title used when a script does not provide one
+const defaultTitle = "notification"
+
+// SenderFactory creates the senders that notifications are sent with, so that they can be replaced when
+// testing scripts.
+type SenderFactory interface {
+	Telegram(config settings.WorkflowSettingsNotificationsTelegram, chatIds []int64) notifications.Sender
+	Slack(config settings.WorkflowSettingsNotificationsSlack, channelIds []string) notifications.Sender
+	Desktop(iconPath string) notifications.Sender
+}
+
+type defaultSenderFactory struct{}
+
+func (defaultSenderFactory) Telegram(config settings.WorkflowSettingsNotificationsTelegram, chatIds []int64) notifications.Sender {
+	return notifications.NewTelegramNotificationFromSettings(config, chatIds...)
+}
+
+func (defaultSenderFactory) Slack(config settings.WorkflowSettingsNotificationsSlack, channelIds []string) notifications.Sender {
+	return notifications.NewSlackNotification(config.WebhookUrl, channelIds...)
+}
+
+func (defaultSenderFactory) Desktop(iconPath string) notifications.Sender {
+	return notifications.NewDesktopNotification(iconPath)
+}
+
+// ScriptNotifications is the `notifications` object, which scripts use to send notifications with the
+// integrations configured in the `settings.notifications` section.  Settings are read when a notification
+// is sent, so that the current settings are used after the workflow is reloaded.
+type ScriptNotifications struct {
+	getSettings func() *settings.Settings
+	getIconPath func() string
+	senders     SenderFactory
+	// returns true if notifications should not be sent, such as during a dry run
+	IsDryRun func() bool
+}
+
+func Create(getSettings func() *settings.Settings, getIconPath func() string) *ScriptNotifications {
+	return CreateWithSenders(getSettings, getIconPath, defaultSenderFactory{})
+}
+
+func CreateWithSenders(getSettings func() *settings.Settings, getIconPath func() string, senders SenderFactory) *ScriptNotifications {
+	return &ScriptNotifications{
+		getSettings: getSettings,
+		getIconPath: getIconPath,
+		senders:     senders,
+	}
+}
+
+func (sn *ScriptNotifications) GetName() string {
+	return name
+}
+
+func (ex *ScriptNotifications) OnInstall(engine types.JavaScriptEngineContract) {
+	ex.IsDryRun = engine.IsDryRun
+	engine.GetVm().Set(ex.GetName(), ex)
+}
+
+func (sn *ScriptNotifications) notificationSettings() settings.WorkflowSettingsNotifications {
+	if s := sn.getSettings(); s != nil {
+		return s.Notifications
+	}
+
+	return settings.WorkflowSettingsNotifications{}
+}
+
+// Telegram returns a new Telegram message, which is sent to the configured chat ids unless others are
+// provided with `To()`.
+func (sn *ScriptNotifications) Telegram() *TelegramMessage {
+	return &TelegramMessage{sn: sn, title: defaultTitle}
+}
+
+// Slack returns a new Slack message, which is sent to the configured channels unless others are provided
+// with `To()`.
+func (sn *ScriptNotifications) Slack() *SlackMessage {
+	return &SlackMessage{sn: sn, title: defaultTitle}
+}
+
+// Desktop returns a new desktop notification.
+func (sn *ScriptNotifications) Desktop() *DesktopMessage {
+	return &DesktopMessage{sn: sn, title: defaultTitle}
+}
+
+// send reports a failure to send a notification, and returns true if it was sent.  Notifications are not
+// sent during a dry run, but are reported as sent.
+func (sn *ScriptNotifications) send(integration string, sender notifications.Sender, title string, message string) bool {
+	if sn.IsDryRun != nil && sn.IsDryRun() {
+		support.SkippedMessageWithSymbol(messages.DryRunScriptSkipped("notifications." + integration + "()"))
+		return true
+	}
+
+	if err := sender.Send(title, message); err != nil {
+		support.FailureMessageWithXMark(messages.NotificationFailed(integration, err.Error()))
+		return false
+	}
+
+	return true
+}
+
+type TelegramMessage struct {
+	sn      *ScriptNotifications
+	title   string
+	message string
+	chatIds []string
+}
+
+func (tm *TelegramMessage) Message(message string, title ...string) *TelegramMessage {
+	tm.message = message
+
+	if len(title) > 0 {
+		tm.title = title[0]
+	}
+
+	return tm
+}
+
+func (tm *TelegramMessage) To(chatIds ...string) *TelegramMessage {
+	tm.chatIds = append(tm.chatIds, chatIds...)
+
+	return tm
+}
+
+func (tm *TelegramMessage) Send() bool {
+	config := tm.sn.notificationSettings().Telegram
+
+	chatIds := tm.chatIds
+	if len(chatIds) == 0 {
+		chatIds = config.ChatIds
+	}
+
+	ids, err := notifications.ParseChatIds(chatIds)
+	if err != nil {
+		support.FailureMessageWithXMark(messages.NotificationFailed("telegram", err.Error()))
+		return false
+	}
+
+	return tm.sn.send("telegram", tm.sn.senders.Telegram(config, ids), tm.title, tm.message)
+}
+
+type SlackMessage struct {
+	sn         *ScriptNotifications
+	title      string
+	message    string
+	channelIds []string
+}
+
+func (sm *SlackMessage) Message(message string, title ...string) *SlackMessage {
+	sm.message = message
+
+	if len(title) > 0 {
+		sm.title = title[0]
+	}
+
+	return sm
+}
+
+func (sm *SlackMessage) To(channelIds ...string) *SlackMessage {
+	sm.channelIds = append(sm.channelIds, channelIds...)
+
+	return sm
+}
+
+func (sm *SlackMessage) Send() bool {
+	config := sm.sn.notificationSettings().Slack
+
+	channelIds := sm.channelIds
+	if len(channelIds) == 0 {
+		channelIds = config.ChannelIds
+	}
+
+	return sm.sn.send("slack", sm.sn.senders.Slack(config, channelIds), sm.title, sm.message)
+}
+
+type DesktopMessage struct {
+	sn      *ScriptNotifications
+	title   string
+	message string
+}
+
+func (dm *DesktopMessage) Message(message string, title ...string) *DesktopMessage {
+	dm.message = message
+
+	if len(title) > 0 {
+		dm.title = title[0]
+	}
+
+	return dm
+}
+
+func (dm *DesktopMessage) Send() bool {
+	return dm.sn.send("desktop", dm.sn.senders.Desktop(dm.sn.getIconPath()), dm.title, dm.message)
+}
@@ -0,0 +1,129 @@
+package notificationsextension_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/robertkrimen/otto"
+	"github.com/stackup-app/stackup/lib/notifications"
+	notificationsextension "github.com/stackup-app/stackup/lib/scripting/extensions/notifications_extension"
+	"github.com/stackup-app/stackup/lib/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+type sentNotification struct {
+	integration string
+	recipients  any
+	title       string
+	message     string
+}
+
+// recordingSenders records notifications instead of sending them.
+type recordingSenders struct {
+	sent []sentNotification
+}
+
+type recordingSender struct {
+	senders     *recordingSenders
+	integration string
+	recipients  any
+}
+
+func (s *recordingSender) Send(title, message string) error {
+	s.senders.sent = append(s.senders.sent, sentNotification{s.integration, s.recipients, title, message})
+	return nil
+}
+
+func (rs *recordingSenders) Telegram(config settings.WorkflowSettingsNotificationsTelegram, chatIds []int64) notifications.Sender {
+	return &recordingSender{rs, "telegram", chatIds}
+}
+
+func (rs *recordingSenders) Slack(config settings.WorkflowSettingsNotificationsSlack, channelIds []string) notifications.Sender {
+	return &recordingSender{rs, "slack", channelIds}
+}
+
+func (rs *recordingSenders) Desktop(iconPath string) notifications.Sender {
+	return &recordingSender{rs, "desktop", iconPath}
+}
+
+func newVm(ext *notificationsextension.ScriptNotifications) *otto.Otto {
+	vm := otto.New()
+	vm.Set(ext.GetName(), ext)
+
+	return vm
+}
+
+func TestNotificationsUseConfiguredRecipients(t *testing.T) {
+	s := &settings.Settings{}
+	s.Notifications.Telegram.ChatIds = []string{"1001", "1002"}
+	s.Notifications.Slack.ChannelIds = []string{"#dev"}
+
+	senders := &recordingSenders{}
+	ext := notificationsextension.CreateWithSenders(func() *settings.Settings { return s }, func() string { return "icon.png" }, senders)
+	vm := newVm(ext)
+
+	for _, script := range []string{
+		`notifications.Telegram().Message("hello").Send()`,
+		`notifications.Telegram().Message("hello", "title").To("42").Send()`,
+		`notifications.Slack().Message("hello").Send()`,
+		`notifications.Slack().Message("hello").To("#ops").Send()`,
+		`notifications.Desktop().Message("hello", "some title").Send()`,
+	} {
+		result, err := vm.Run(script)
+		assert.NoError(t, err)
+		assert.Equal(t, "true", result.String(), script)
+	}
+
+	assert.Equal(t, []sentNotification{
+		{"telegram", []int64{1001, 1002}, "notification", "hello"},
+		{"telegram", []int64{42}, "title", "hello"},
+		{"slack", []string{"#dev"}, "notification", "hello"},
+		{"slack", []string{"#ops"}, "notification", "hello"},
+		{"desktop", "icon.png", "some title", "hello"},
+	}, senders.sent)
+
+	// invalid chat ids are reported without sending anything
+	result, _ := vm.Run(`notifications.Telegram().Message("hello").To("abc").Send()`)
+	assert.Equal(t, "false", result.String())
+	assert.Len(t, senders.sent, 5)
+}
+
+func TestNotificationsSendToConfiguredServers(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, r.URL.Path+" "+body["text"].(string))
+
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	s := &settings.Settings{}
+	s.Notifications.Telegram.APIKey = "token123"
+	s.Notifications.Telegram.ApiUrl = server.URL
+	s.Notifications.Telegram.ChatIds = []string{"1001"}
+	s.Notifications.Slack.WebhookUrl = server.URL + "/hooks/abc"
+	s.Notifications.Slack.ChannelIds = []string{"#dev"}
+
+	vm := newVm(notificationsextension.Create(func() *settings.Settings { return s }, func() string { return "" }))
+
+	result, err := vm.Run(`notifications.Telegram().Message("deployed").Send() && notifications.Slack().Message("deployed").Send()`)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", result.String())
+	assert.Equal(t, []string{"/bottoken123/sendMessage notification\ndeployed", "/hooks/abc deployed"}, requests)
+}
+
+func TestNotificationsAreNotSentDuringADryRun(t *testing.T) {
+	senders := &recordingSenders{}
+	ext := notificationsextension.CreateWithSenders(func() *settings.Settings { return &settings.Settings{} }, func() string { return "" }, senders)
+	ext.IsDryRun = func() bool { return true }
+	vm := newVm(ext)
+
+	result, err := vm.Run(`notifications.Slack().Message("hello").To("#ops").Send()`)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", result.String())
+	assert.Empty(t, senders.sent)
+}
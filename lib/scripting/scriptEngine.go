@@ -12,6 +12,7 @@ import (
 	fsextension "github.com/stackup-app/stackup/lib/scripting/extensions/fs_extension"
 	functionsextension "github.com/stackup-app/stackup/lib/scripting/extensions/functions_extension"
 	netextension "github.com/stackup-app/stackup/lib/scripting/extensions/net_extension"
+	notificationsextension "github.com/stackup-app/stackup/lib/scripting/extensions/notifications_extension"
 	varsextension "github.com/stackup-app/stackup/lib/scripting/extensions/vars_extension"
 	"github.com/stackup-app/stackup/lib/support"
 	"github.com/stackup-app/stackup/lib/types"
@@ -25,6 +26,9 @@ type JavaScriptEngine struct {
 	InstalledExtensions *sync.Map
 	initialized         bool
 	AppIntf             types.AppInterface
+	// when true, script functions with side effects, such as running commands, writing files, making
+	// requests or sending notifications, do nothing and return empty results
+	DryRun bool
 	types.JavaScriptEngineContract
 }
 
@@ -43,6 +47,10 @@ func (e *JavaScriptEngine) GetApplicationIconPath() string {
 	return e.App().GetApplicationIconPath()
 }
 
+func (e *JavaScriptEngine) IsDryRun() bool {
+	return e.DryRun
+}
+
 func (e *JavaScriptEngine) GetAppVars() *sync.Map {
 	return e.App().GetVars()
 }
@@ -68,7 +76,6 @@ func (e *JavaScriptEngine) Initialize() {
 
 	e.initializeExtensions()
 
-	// CreateScripNotificationsObject(workflow, e)
 	e.initialized = true
 
 	e.CreateAppVariables(e.App().GetVars())
@@ -88,6 +95,7 @@ func (e *JavaScriptEngine) initializeExtensions() {
 	appextension.Create().OnInstall(engine)
 	fsextension.Create().OnInstall(engine)
 	functionsextension.Create(engine).OnInstall(engine)
+	notificationsextension.Create(e.App().GetSettings, e.GetApplicationIconPath).OnInstall(engine)
 }
 
 func (e *JavaScriptEngine) CreateAppVariables(vars *sync.Map) {
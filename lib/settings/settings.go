@@ -13,6 +13,13 @@ type Settings struct {
 	Gateway                WorkflowSettingsGateway       `yaml:"gateway"`
 	Notifications          WorkflowSettingsNotifications `yaml:"notifications"`
 	Debug                  bool                          `yaml:"debug"`
+	HotReload              *bool                         `yaml:"hot-reload"`
+}
+
+// IsHotReloadEnabled returns true if the workflow should be reloaded when its configuration files change,
+// which is the default.
+func (s *Settings) IsHotReloadEnabled() bool {
+	return s.HotReload == nil || *s.HotReload
 }
 
 type GatewayBlockAllowListsContract interface {
@@ -30,7 +37,7 @@ type GatewayBlockAllowLists struct {
 }
 
 type GatewayContentTypes struct {
-	GatewayBlockAllowLists
+	GatewayBlockAllowLists `yaml:",inline"`
 }
 
 type WorkflowSettingsGateway struct {
@@ -64,19 +71,38 @@ type WorkflowSettingsDefaults struct {
 }
 
 type WorkflowSettingsDefaultsTasks struct {
-	Silent    bool     `yaml:"silent"`
-	Path      string   `yaml:"path"`
-	Platforms []string `yaml:"platforms"`
+	Silent      bool     `yaml:"silent"`
+	Path        string   `yaml:"path"`
+	Shell       string   `yaml:"shell"`
+	StopSignal  string   `yaml:"stop-signal"`
+	StopTimeout string   `yaml:"stop-timeout"`
+	Platforms   []string `yaml:"platforms"`
 }
 
 type WorkflowSettingsNotifications struct {
 	Telegram WorkflowSettingsNotificationsTelegram `yaml:"telegram"`
 	Slack    WorkflowSettingsNotificationsSlack    `yaml:"slack"`
+	Webhook  WorkflowSettingsNotificationsWebhook  `yaml:"webhook"`
+	Email    WorkflowSettingsNotificationsEmail    `yaml:"email"`
+	Rules    []WorkflowSettingsNotificationRule    `yaml:"rules"`
+}
+
+// WorkflowSettingsNotificationRule sends a notification to `Channel` when the event `On` occurs.  `Title`
+// and `Message` may contain placeholders such as `{task}` and `{exit-code}`.
+type WorkflowSettingsNotificationRule struct {
+	On       string   `yaml:"on"`
+	Tasks    []string `yaml:"tasks"`
+	Channel  string   `yaml:"channel"`
+	To       []string `yaml:"to"`
+	Title    string   `yaml:"title"`
+	Message  string   `yaml:"message"`
+	Duration string   `yaml:"duration"`
 }
 
 type WorkflowSettingsNotificationsTelegram struct {
 	APIKey  string   `yaml:"api-key"`
 	ChatIds []string `yaml:"chat-ids"`
+	ApiUrl  string   `yaml:"api-url"`
 }
 
 type WorkflowSettingsNotificationsSlack struct {
@@ -84,6 +110,26 @@ type WorkflowSettingsNotificationsSlack struct {
 	ChannelIds []string `yaml:"channel-ids"`
 }
 
+// WorkflowSettingsNotificationsWebhook sends notifications to a webhook.  `Body` is a JSON template that may
+// contain the `{title}` and `{message}` placeholders, and each of the `Headers` is formatted as `Name: value`.
+type WorkflowSettingsNotificationsWebhook struct {
+	Url     string   `yaml:"url"`
+	Method  string   `yaml:"method"`
+	Headers []string `yaml:"headers"`
+	Body    string   `yaml:"body"`
+}
+
+// WorkflowSettingsNotificationsEmail sends notifications as emails through an SMTP server.
+type WorkflowSettingsNotificationsEmail struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	StartTls bool     `yaml:"starttls"`
+}
+
 func arrayContains[T comparable](array1 []T, array2 any) bool {
 	// Create a map to store the items in array1
 	items := make(map[T]bool)
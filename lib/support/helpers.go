@@ -1,12 +1,10 @@
 package support
 
 import (
-	"fmt"
 	"os"
-	"os/exec"
-	"strings"
 
 	"github.com/logrusorgru/aurora"
+	"github.com/stackup-app/stackup/lib/utils"
 )
 
 const (
@@ -14,23 +12,23 @@ const (
 )
 
 func SkippedMessageWithSymbol(msg string) {
-	fmt.Println(MessageIndentation + aurora.White(msg).String() + aurora.BrightYellow(" [skipped] ⚬").String())
+	writeLine(MessageIndentation + aurora.White(msg).String() + aurora.BrightYellow(" [skipped] ⚬").String())
 }
 
 func SkippedMessageWitReason(msg string, reason string) {
-	fmt.Println(MessageIndentation + aurora.White(reason).String() + aurora.BrightYellow(" [skipped] ⚬").String())
+	writeLine(MessageIndentation + aurora.White(reason).String() + aurora.BrightYellow(" [skipped] ⚬").String())
 }
 
 func SuccessMessageWithCheck(msg string) {
-	fmt.Println(MessageIndentation + aurora.White(msg).String() + aurora.BrightGreen(" ✓").String())
+	writeLine(MessageIndentation + aurora.White(msg).String() + aurora.BrightGreen(" ✓").String())
 }
 
 func FailureMessageWithXMark(msg string) {
-	fmt.Println(MessageIndentation + aurora.White(msg).String() + aurora.BrightRed(" ✗").String())
+	writeLine(MessageIndentation + aurora.White(msg).String() + aurora.BrightRed(" ✗").String())
 }
 
 func WarningMessage(msg string) {
-	fmt.Println(MessageIndentation + aurora.BrightYellow(msg).String())
+	writeLine(MessageIndentation + aurora.BrightYellow(msg).String())
 }
 
 func StatusMessageLine(msg string, highlight bool) {
@@ -38,7 +36,7 @@ func StatusMessageLine(msg string, highlight bool) {
 	if highlight {
 		text = aurora.BrightYellow(msg)
 	}
-	fmt.Println(MessageIndentation + text.String())
+	writeLine(MessageIndentation + text.String())
 }
 
 func StatusMessage(msg string, highlight bool) {
@@ -46,19 +44,19 @@ func StatusMessage(msg string, highlight bool) {
 	if highlight {
 		text = aurora.BrightYellow(msg)
 	}
-	fmt.Print(MessageIndentation + text.String())
+	write(MessageIndentation + text.String())
 }
 
 func PrintCheckMark() {
-	fmt.Print(aurora.BrightGreen(" ✓").String())
+	write(aurora.BrightGreen(" ✓").String())
 }
 
 func PrintCheckMarkLine() {
-	fmt.Print(aurora.BrightGreen(" ✓\n").String())
+	write(aurora.BrightGreen(" ✓\n").String())
 }
 
 func PrintXMarkLine() {
-	fmt.Print(aurora.BrightRed(" ✗\n").String())
+	write(aurora.BrightRed(" ✗\n").String())
 }
 
 // The function `FindExistingFile` takes a list of filenames and a default filename, and returns the
@@ -74,16 +72,12 @@ func FindExistingFile(filenames []string, defaultFilename string) string {
 }
 
 // The function `GetCommandOutput` takes a command as input, executes it, and returns the output as a
-// string.
-func GetCommandOutput(command string) string {
-	parts := strings.Split(command, " ")
-
-	cmd := exec.Command(parts[0], parts[1:]...)
-
-	outputBytes, err := cmd.Output()
+// string.  An optional `shell` may be specified, see `utils.BuildCommandArgs`.
+func GetCommandOutput(command string, shell ...string) string {
+	output, err := utils.CommandOutput(command, utils.CommandOptions{Shell: utils.FirstNonEmpty(shell...)})
 	if err != nil {
 		return ""
 	}
 
-	return strings.TrimSpace(string(outputBytes))
+	return output
 }
@@ -1,6 +1,10 @@
 package support
 
 import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -52,3 +56,51 @@ func TestFindExistingFileWithExistingDefaultFilename(t *testing.T) {
 		t.Errorf("Expected '%s', but got '%s'", defaultFilename, existingDefaultFile)
 	}
 }
+
+func TestPrefixedWriter(t *testing.T) {
+	var output bytes.Buffer
+	writer := NewPrefixedWriter(&output, "api")
+	prefix := PrefixColor("api")("[api]").String() + " "
+
+	writer.Write([]byte("listening\nready"))
+	if output.String() != prefix+"listening\n" {
+		t.Errorf("Expected complete lines to be prefixed, but got '%s'", output.String())
+	}
+
+	writer.Write([]byte(" to accept\n"))
+	writer.Write([]byte("done"))
+	writer.Flush()
+
+	expected := prefix + "listening\n" + prefix + "ready to accept\n" + prefix + "done\n"
+	if output.String() != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, output.String())
+	}
+}
+
+func TestMessagesAndPrefixedLinesAreNotInterleaved(t *testing.T) {
+	var output bytes.Buffer
+	SetMessageOutput(&output)
+	defer SetMessageOutput(os.Stdout)
+
+	writer := NewPrefixedWriter(&output, "api")
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SuccessMessageWithCheck("started")
+		}()
+		go func() {
+			defer wg.Done()
+			writer.Write([]byte("listening\n"))
+		}()
+	}
+
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	if len(lines) != 100 {
+		t.Errorf("Expected 100 lines, but got %d", len(lines))
+	}
+}
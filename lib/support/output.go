@@ -0,0 +1,127 @@
+package support
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/logrusorgru/aurora"
+)
+
+var prefixColors = []func(arg interface{}) aurora.Value{
+	aurora.Cyan,
+	aurora.Magenta,
+	aurora.Green,
+	aurora.Yellow,
+	aurora.Blue,
+	aurora.BrightCyan,
+	aurora.BrightMagenta,
+	aurora.BrightGreen,
+	aurora.BrightBlue,
+}
+
+// messageOutput is where status, success, failure and warning messages are written.
+var messageOutput io.Writer = os.Stdout
+
+// outputLock ensures that messages and the lines written by different prefixed writers are never interleaved.
+var outputLock sync.Mutex
+
+// SetMessageOutput changes where status, success, failure and warning messages are written, which is
+// stdout by default.
+func SetMessageOutput(w io.Writer) {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	messageOutput = w
+}
+
+func write(text string) {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	fmt.Fprint(messageOutput, text)
+}
+
+func writeLine(text string) {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	fmt.Fprintln(messageOutput, text)
+}
+
+// PrefixedWriter is an io.Writer that writes each line to `Writer` preceded by a colourised `[name]`
+// prefix.  Incomplete lines are buffered until they are completed or the writer is flushed.
+type PrefixedWriter struct {
+	Writer io.Writer
+	prefix []byte
+	buffer []byte
+	lock   sync.Mutex
+}
+
+// PrefixColor returns the colour used for the prefix `name`, which is the same every time for a given name.
+func PrefixColor(name string) func(arg interface{}) aurora.Value {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+
+	return prefixColors[hash.Sum32()%uint32(len(prefixColors))]
+}
+
+func NewPrefixedWriter(w io.Writer, name string) *PrefixedWriter {
+	return &PrefixedWriter{
+		Writer: w,
+		prefix: []byte(PrefixColor(name)("["+name+"]").String() + " "),
+	}
+}
+
+func (pw *PrefixedWriter) Write(p []byte) (int, error) {
+	pw.lock.Lock()
+	defer pw.lock.Unlock()
+
+	pw.buffer = append(pw.buffer, p...)
+
+	index := bytes.LastIndexByte(pw.buffer, '\n')
+	if index == -1 {
+		return len(p), nil
+	}
+
+	lines := pw.buffer[:index+1]
+	pw.buffer = append([]byte{}, pw.buffer[index+1:]...)
+
+	return len(p), pw.writeLines(lines)
+}
+
+// Flush writes any incomplete line that has been buffered.
+func (pw *PrefixedWriter) Flush() error {
+	pw.lock.Lock()
+	defer pw.lock.Unlock()
+
+	if len(pw.buffer) == 0 {
+		return nil
+	}
+
+	lines := append(pw.buffer, '\n')
+	pw.buffer = nil
+
+	return pw.writeLines(lines)
+}
+
+func (pw *PrefixedWriter) writeLines(lines []byte) error {
+	var result bytes.Buffer
+
+	for _, line := range bytes.SplitAfter(lines, []byte("\n")) {
+		if len(line) > 0 {
+			result.Write(pw.prefix)
+			result.Write(line)
+		}
+	}
+
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	_, err := pw.Writer.Write(result.Bytes())
+
+	return err
+}
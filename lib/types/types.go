@@ -1,6 +1,7 @@
 package types
 
 import (
+	"os"
 	"os/exec"
 	"sync"
 
@@ -10,6 +11,8 @@ import (
 
 type CommandCallback func(cmd *exec.Cmd)
 
+type SignalCommandCallback func(cmd *exec.Cmd, sig os.Signal)
+
 type SetProcessCallback func(key, value any)
 
 type AppInterface interface {
@@ -32,6 +35,7 @@ type JavaScriptEngineContract interface {
 	GetGateway() GatewayContract
 	GetAppVars() *sync.Map
 	GetFindTaskById(id string) (any, bool)
+	IsDryRun() bool
 }
 
 type AppWorkflowTaskContract interface {
@@ -0,0 +1,369 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ShellNone disables shell execution: the command line is split into words and executed directly.
+const ShellNone = "none"
+
+// CommandOptions configures how a command line is turned into an `exec.Cmd`.  `Env` contains `KEY=value`
+// entries that are added to the current process environment.  If `Stdout` or `Stderr` are nil, output is
+// sent to `os.Stdout` and `os.Stderr`.
+type CommandOptions struct {
+	Cwd    string
+	Shell  string
+	Env    []string
+	Silent bool
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+var ErrEmptyCommand = errors.New("empty command")
+var ErrCommandTimedOut = errors.New("command timed out")
+var ErrUnterminatedQuote = errors.New("unterminated quoted string")
+var ErrTrailingBackslash = errors.New("trailing backslash")
+
+// ParseCommandLine splits `input` into words using POSIX shell quoting rules: single quotes preserve
+// their contents literally, double quotes allow `\` to escape `$`, "`", `"`, `\` and newlines, and an
+// unquoted backslash escapes the character that follows it.
+func ParseCommandLine(input string) ([]string, error) {
+	result := []string{}
+	runes := []rune(input)
+
+	var word strings.Builder
+	inWord := false
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, ErrTrailingBackslash
+			}
+			i++
+			// a backslash-newline pair is a line continuation
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+
+		case r == '\'':
+			end := indexRune(runes, '\'', i+1)
+			if end == -1 {
+				return nil, ErrUnterminatedQuote
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			i = end
+			inWord = true
+
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("$`\"\\\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, ErrUnterminatedQuote
+			}
+			inWord = true
+
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				result = append(result, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		result = append(result, word.String())
+	}
+
+	return result, nil
+}
+
+func indexRune(runes []rune, r rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// CommandNeedsShell returns true if `input` contains unquoted shell syntax such as pipes, redirects,
+// command chaining, variable expansion, globs or comments that can only be handled by running it through
+// a shell.
+func CommandNeedsShell(input string) bool {
+	runes := []rune(input)
+	wordStart := true
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '\\':
+			i++
+			wordStart = false
+		case r == '\'':
+			if end := indexRune(runes, '\'', i+1); end != -1 {
+				i = end
+			}
+			wordStart = false
+		case r == '"':
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' {
+					i++
+				} else if runes[i] == '$' || runes[i] == '`' {
+					return true
+				}
+			}
+			wordStart = false
+		case r == ' ' || r == '\t':
+			wordStart = true
+		case strings.ContainsRune("|&;<>()$`*?[\n", r):
+			return true
+		case (r == '~' || r == '#') && wordStart:
+			return true
+		default:
+			wordStart = false
+		}
+	}
+
+	return false
+}
+
+// isEnvAssignment returns true if `word` has the form `NAME=value`.
+func isEnvAssignment(word string) bool {
+	name, _, found := strings.Cut(word, "=")
+
+	return found && MatchesPattern(name, `^[A-Za-z_][A-Za-z0-9_]*$`)
+}
+
+// DefaultShell returns the shell used to run commands that contain shell syntax when a task does not
+// specify one.
+func DefaultShell() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C"}
+	}
+
+	return []string{"sh", "-c"}
+}
+
+func shellCommandFlag(shell string) string {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(shell)), ".exe")
+
+	switch name {
+	case "cmd":
+		return "/C"
+	case "powershell", "pwsh":
+		return "-Command"
+	}
+
+	return "-c"
+}
+
+// BuildCommandArgs returns the program and arguments used to run `input`, along with any leading
+// `NAME=value` environment assignments.  `shell` may be empty to run the command through the default
+// shell only when it contains shell syntax, "none" to never use a shell, or a shell such as `bash`
+// or `sh -c`; if no flag is given the shell's "run a command" flag is appended automatically.
+func BuildCommandArgs(input string, shell string) ([]string, []string, error) {
+	shell = strings.TrimSpace(shell)
+	input = strings.TrimSpace(input)
+
+	if input == "" {
+		return nil, nil, ErrEmptyCommand
+	}
+
+	if shell == "" && CommandNeedsShell(input) {
+		return append(DefaultShell(), input), []string{}, nil
+	}
+
+	if shell != "" && !strings.EqualFold(shell, ShellNone) {
+		args, err := ParseCommandLine(shell)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(args) == 1 {
+			args = append(args, shellCommandFlag(args[0]))
+		}
+
+		return append(args, input), []string{}, nil
+	}
+
+	words, err := ParseCommandLine(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env := []string{}
+	for len(words) > 0 && isEnvAssignment(words[0]) {
+		env = append(env, words[0])
+		words = words[1:]
+	}
+
+	if len(words) == 0 {
+		return nil, nil, ErrEmptyCommand
+	}
+
+	return words, env, nil
+}
+
+// NewCommand creates an `exec.Cmd` for the command line `input` using the shell and working directory
+// specified in `opts`.  Output is sent to stdout/stderr unless `opts.Silent` is true.
+func NewCommand(input string, opts CommandOptions) (*exec.Cmd, error) {
+	args, env, err := BuildCommandArgs(input, opts.Shell)
+	if err != nil {
+		return nil, err
+	}
+
+	c := exec.Command(args[0], args[1:]...)
+	c.Dir = opts.Cwd
+
+	if len(opts.Env) > 0 || len(env) > 0 {
+		c.Env = CombineArrays(os.Environ(), opts.Env, env)
+	}
+
+	if !opts.Silent {
+		c.Stdout = firstWriter(opts.Stdout, os.Stdout)
+		c.Stderr = firstWriter(opts.Stderr, os.Stderr)
+	}
+
+	return c, nil
+}
+
+func firstWriter(writers ...io.Writer) io.Writer {
+	for _, w := range writers {
+		if w != nil {
+			return w
+		}
+	}
+
+	return nil
+}
+
+// RunCommand runs the command line `input` and waits for it to complete.
+func RunCommand(input string, opts CommandOptions) (*exec.Cmd, error) {
+	c, err := NewCommand(input, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, c.Run()
+}
+
+// RunWithTimeout starts `c` and waits for it to complete.  If `timeout` is greater than zero and the
+// command has not completed in time, `kill` is called to terminate it and `ErrCommandTimedOut` is
+// returned.  If `kill` is nil, only the process itself is killed.
+func RunWithTimeout(c *exec.Cmd, timeout time.Duration, kill func(c *exec.Cmd)) error {
+	if timeout <= 0 {
+		return c.Run()
+	}
+
+	// don't wait forever for output from orphaned child processes once the command has been killed
+	c.WaitDelay = 5 * time.Second
+
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Wait()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
+
+	if kill != nil {
+		kill(c)
+	} else {
+		c.Process.Kill()
+	}
+
+	<-done
+
+	return ErrCommandTimedOut
+}
+
+// ExitCode returns the exit code for the result of running a command: 0 if `err` is nil, the command's
+// exit code if it exited with a non-zero code, 124 if it timed out, and 1 for any other error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if errors.Is(err, ErrCommandTimedOut) {
+		return 124
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+
+	return 1
+}
+
+// CommandOutput runs the command line `input` and returns its trimmed stdout.
+func CommandOutput(input string, opts CommandOptions) (string, error) {
+	c, err := NewCommand(input, opts)
+	if err != nil {
+		return "", err
+	}
+
+	c.Stdout = nil
+	c.Stderr = nil
+
+	outputBytes, err := c.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(outputBytes)), nil
+}
+
+var signalNames = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
+// ParseSignal returns the signal named `name`, such as "SIGTERM" or "term".  Returns false if the
+// name is not a supported signal.
+func ParseSignal(name string) (os.Signal, bool) {
+	name = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+	result, found := signalNames[name]
+
+	return result, found
+}
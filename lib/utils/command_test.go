@@ -0,0 +1,178 @@
+package utils_test
+
+import (
+	"os/exec"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	tests := map[string][]string{
+		`php artisan migrate`:                {"php", "artisan", "migrate"},
+		`  php   artisan  `:                  {"php", "artisan"},
+		`echo 'hello world'`:                 {"echo", "hello world"},
+		`echo "hello   world"`:               {"echo", "hello   world"},
+		`echo "say \"hi\"" 'it''s'`:          {"echo", `say "hi"`, "its"},
+		`echo hello\ world`:                  {"echo", "hello world"},
+		`echo "a\b" 'c\d'`:                   {"echo", `a\b`, `c\d`},
+		`git commit -m "fix: \$HOME is set"`: {"git", "commit", "-m", "fix: $HOME is set"},
+		`printf ""`:                          {"printf", ""},
+		`node --eval='console.log("x y")'`:   {"node", `--eval=console.log("x y")`},
+		"ls \\\n -la":                        {"ls", "-la"},
+		``:                                   {},
+	}
+
+	for input, expected := range tests {
+		words, err := utils.ParseCommandLine(input)
+		assert.NoError(t, err, "input: %s", input)
+		assert.Equal(t, expected, words, "input: %s", input)
+	}
+
+	_, err := utils.ParseCommandLine(`echo "unterminated`)
+	assert.ErrorIs(t, err, utils.ErrUnterminatedQuote)
+
+	_, err = utils.ParseCommandLine(`echo 'unterminated`)
+	assert.ErrorIs(t, err, utils.ErrUnterminatedQuote)
+
+	_, err = utils.ParseCommandLine(`echo test\`)
+	assert.ErrorIs(t, err, utils.ErrTrailingBackslash)
+}
+
+func TestCommandNeedsShell(t *testing.T) {
+	assert.False(t, utils.CommandNeedsShell(`php artisan migrate --seed`))
+	assert.False(t, utils.CommandNeedsShell(`echo 'a | b && c > d'`))
+	assert.False(t, utils.CommandNeedsShell(`echo "a | b"`))
+	assert.False(t, utils.CommandNeedsShell(`NODE_ENV=production npm run build`))
+	assert.False(t, utils.CommandNeedsShell(`echo a\|b`))
+	assert.False(t, utils.CommandNeedsShell(`git checkout issue#42`))
+
+	assert.True(t, utils.CommandNeedsShell(`cat composer.json | jq .require`))
+	assert.True(t, utils.CommandNeedsShell(`npm ci && npm run build`))
+	assert.True(t, utils.CommandNeedsShell(`php artisan migrate > /dev/null`))
+	assert.True(t, utils.CommandNeedsShell(`echo $HOME`))
+	assert.True(t, utils.CommandNeedsShell(`echo "$HOME"`))
+	assert.True(t, utils.CommandNeedsShell(`rm *.log`))
+	assert.True(t, utils.CommandNeedsShell(`ls ~/projects`))
+	assert.True(t, utils.CommandNeedsShell(`npm run build # compile assets`))
+}
+
+func TestBuildCommandArgs(t *testing.T) {
+	args, env, err := utils.BuildCommandArgs(`NODE_ENV=dev PORT=3000 npm run "dev server"`, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"npm", "run", "dev server"}, args)
+	assert.Equal(t, []string{"NODE_ENV=dev", "PORT=3000"}, env)
+
+	args, _, err = utils.BuildCommandArgs(`npm ci && npm run build`, "")
+	assert.NoError(t, err)
+	assert.Equal(t, append(utils.DefaultShell(), `npm ci && npm run build`), args)
+
+	args, _, err = utils.BuildCommandArgs(`echo a | cat`, "none")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"echo", "a", "|", "cat"}, args)
+
+	args, _, err = utils.BuildCommandArgs(`echo hello`, "bash")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bash", "-c", "echo hello"}, args)
+
+	args, _, err = utils.BuildCommandArgs(`echo hello`, "bash -lc")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bash", "-lc", "echo hello"}, args)
+
+	args, _, err = utils.BuildCommandArgs(`echo hello`, "cmd.exe")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cmd.exe", "/C", "echo hello"}, args)
+
+	_, _, err = utils.BuildCommandArgs(`   `, "")
+	assert.ErrorIs(t, err, utils.ErrEmptyCommand)
+
+	_, _, err = utils.BuildCommandArgs(`FOO=bar`, "none")
+	assert.ErrorIs(t, err, utils.ErrEmptyCommand)
+}
+
+func TestCommandOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	output, err := utils.CommandOutput(`printf 'one two' | tr a-z A-Z`, utils.CommandOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ONE TWO", output)
+
+	output, err = utils.CommandOutput(`GREETING=hi sh -c 'echo "$GREETING there"'`, utils.CommandOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "hi there", output)
+
+	output, err = utils.CommandOutput(`echo "$0"`, utils.CommandOptions{Shell: "sh"})
+	assert.NoError(t, err)
+	assert.Equal(t, "sh", output)
+}
+
+func TestNewCommandEnvironment(t *testing.T) {
+	cmd, err := utils.NewCommand(`DEBUG=1 node server.js`, utils.CommandOptions{Env: []string{"PORT=3000", "DEBUG=0"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PORT=3000", "DEBUG=0", "DEBUG=1"}, cmd.Env[len(cmd.Env)-3:])
+
+	cmd, err = utils.NewCommand(`node server.js`, utils.CommandOptions{})
+
+	assert.NoError(t, err)
+	assert.Nil(t, cmd.Env)
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	cmd, _ := utils.NewCommand(`sleep 5`, utils.CommandOptions{Silent: true})
+	killed := false
+	started := time.Now()
+
+	err := utils.RunWithTimeout(cmd, 100*time.Millisecond, func(c *exec.Cmd) {
+		killed = true
+		c.Process.Kill()
+	})
+
+	assert.ErrorIs(t, err, utils.ErrCommandTimedOut)
+	assert.True(t, killed)
+	assert.Less(t, time.Since(started), 4*time.Second)
+
+	cmd, _ = utils.NewCommand(`true`, utils.CommandOptions{Silent: true})
+	assert.NoError(t, utils.RunWithTimeout(cmd, time.Second, nil))
+
+	cmd, _ = utils.NewCommand(`false`, utils.CommandOptions{Silent: true})
+	err = utils.RunWithTimeout(cmd, time.Second, nil)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, utils.ErrCommandTimedOut)
+}
+
+func TestParseSignal(t *testing.T) {
+	sig, found := utils.ParseSignal("SIGTERM")
+	assert.True(t, found)
+	assert.Equal(t, syscall.SIGTERM, sig)
+
+	sig, found = utils.ParseSignal("int")
+	assert.True(t, found)
+	assert.Equal(t, syscall.SIGINT, sig)
+
+	_, found = utils.ParseSignal("SIGNOPE")
+	assert.False(t, found)
+}
+
+func TestExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+
+	assert.Equal(t, 0, utils.ExitCode(nil))
+	assert.Equal(t, 124, utils.ExitCode(utils.ErrCommandTimedOut))
+	assert.Equal(t, 1, utils.ExitCode(utils.ErrEmptyCommand))
+
+	_, err := utils.RunCommand("sh -c 'exit 7'", utils.CommandOptions{Silent: true})
+	assert.Equal(t, 7, utils.ExitCode(err))
+}
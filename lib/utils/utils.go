@@ -39,6 +39,26 @@ func WaitForStartOfNextInterval(interval time.Duration) {
 	time.Sleep(time.Until(time.Now().Truncate(interval).Add(interval)))
 }
 
+// BackoffDelay returns the delay before retry number `attempt` (starting at 1).  If `exponential` is
+// true the delay doubles after each attempt.  The result never exceeds `max` when `max` is greater than zero.
+func BackoffDelay(delay time.Duration, attempt int, exponential bool, max time.Duration) time.Duration {
+	result := delay
+
+	for i := 1; exponential && i < attempt; i++ {
+		result *= 2
+
+		if max > 0 && result >= max {
+			break
+		}
+	}
+
+	if max > 0 && result > max {
+		return max
+	}
+
+	return result
+}
+
 func AbsoluteFilePath(path string) string {
 	if path == "" {
 		return ""
@@ -54,30 +74,12 @@ func AbsoluteFilePath(path string) string {
 }
 
 func RunCommandInPath(input string, dir string, silent bool) (*exec.Cmd, error) {
-	c := StartCommand(input, dir, silent)
-	if err := c.Run(); err != nil {
-		return c, err
-	}
-
-	return c, nil
+	return RunCommand(input, CommandOptions{Cwd: dir, Silent: silent})
 }
 
+// StartCommand creates an `exec.Cmd` for `input`, returning nil if the command line cannot be parsed.
 func StartCommand(input string, cwd string, silent bool) *exec.Cmd {
-	// Split the input into command and arguments
-	parts := strings.Split(input, " ")
-	cmd := parts[0]
-	args := parts[1:]
-
-	c := exec.Command(cmd, args...)
-	c.Dir = cwd
-
-	if !silent {
-		c.Stdout = os.Stdout
-		c.Stderr = os.Stderr
-	} else {
-		c.Stdout = nil
-		c.Stderr = nil
-	}
+	c, _ := NewCommand(input, CommandOptions{Cwd: cwd, Silent: silent})
 
 	return c
 }
@@ -614,6 +616,34 @@ func ImportEnvDefsIntoEnvironment(defs []string) {
 	}
 }
 
+// ExpandEnvDefs expands `$VAR` and `${VAR}` references in the values of the `KEY=value` entries in
+// `defs`.  References are resolved using previous entries in `defs` first, then `lookup`.
+func ExpandEnvDefs(defs []string, lookup func(string) (string, bool)) []string {
+	result := []string{}
+	values := map[string]string{}
+
+	for _, str := range defs {
+		if !strings.Contains(str, "=") {
+			continue
+		}
+
+		parts := strings.SplitN(str, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		value := os.Expand(strings.TrimSpace(parts[1]), func(key string) string {
+			if v, found := values[key]; found {
+				return v
+			}
+			v, _ := lookup(key)
+			return v
+		})
+
+		values[name] = value
+		result = append(result, name+"="+value)
+	}
+
+	return result
+}
+
 func RemoveSubStrings(from string, remove ...string) string {
 	result := from
 	for _, v := range remove {
@@ -234,3 +234,34 @@ func TestOnly(t *testing.T) {
 	assert.Equal(t, []string{}, utils.Only([]string{"a", "b", "c"}, []string{"d", "e"}))
 	assert.Equal(t, []string{}, utils.Only([]string{"a", "b", "c"}, []string{}))
 }
+
+func TestExpandEnvDefs(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		values := map[string]string{"HOME": "/home/user", "PORT": "8000"}
+		v, found := values[name]
+		return v, found
+	}
+
+	result := utils.ExpandEnvDefs([]string{
+		"APP_PORT=$PORT",
+		"APP_URL=http://localhost:${APP_PORT}",
+		"CACHE = $HOME/.cache ",
+		"MISSING=$NOT_DEFINED",
+		"invalid",
+	}, lookup)
+
+	assert.Equal(t, []string{
+		"APP_PORT=8000",
+		"APP_URL=http://localhost:8000",
+		"CACHE=/home/user/.cache",
+		"MISSING=",
+	}, result)
+}
+
+func TestBackoffDelay(t *testing.T) {
+	assert.Equal(t, 2*time.Second, utils.BackoffDelay(2*time.Second, 3, false, 0))
+	assert.Equal(t, time.Second, utils.BackoffDelay(time.Second, 1, true, 0))
+	assert.Equal(t, 4*time.Second, utils.BackoffDelay(time.Second, 3, true, 0))
+	assert.Equal(t, 10*time.Second, utils.BackoffDelay(time.Second, 8, true, 10*time.Second))
+	assert.Equal(t, 10*time.Second, utils.BackoffDelay(time.Second, 5000, true, 10*time.Second))
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+)
+
+// YamlFieldName returns the key used for a struct field in yaml configuration files, and whether the field's
+// own fields are inlined into the parent struct.  Fields without a `yaml` tag are not part of the
+// configuration, so `ok` is false for them.
+func YamlFieldName(field reflect.StructField) (name string, inline bool, ok bool) {
+	tag, found := field.Tag.Lookup("yaml")
+	if !found || tag == "-" {
+		return "", false, false
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+
+	for _, option := range strings.Split(options, ",") {
+		if option == "inline" {
+			return "", true, true
+		}
+	}
+
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	return name, false, true
+}
+
+// YamlFields returns the struct fields of `t` that are part of the configuration, keyed by their yaml key.
+// The fields of inlined structs are included.  `t` may be a pointer to a struct.
+func YamlFields(t reflect.Type) map[string]reflect.StructField {
+	result := map[string]reflect.StructField{}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return result
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline, ok := YamlFieldName(field)
+
+		if !ok {
+			continue
+		}
+
+		if inline {
+			for k, v := range YamlFields(field.Type) {
+				result[k] = v
+			}
+			continue
+		}
+
+		result[name] = field
+	}
+
+	return result
+}
@@ -0,0 +1,374 @@
+package watcher
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/glob"
+)
+
+// ChangeHandler is called with the paths of the files that changed.
+type ChangeHandler func(changed []string)
+
+// fileState is what is compared to determine if a file has changed.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// watchedDir is a directory that is watched recursively.  If `match` is set, only the files whose paths
+// relative to the watcher's base directory match it are watched.
+type watchedDir struct {
+	dir   string
+	match glob.Glob
+}
+
+// Watcher polls a set of files for changes, and calls its handler once no further changes have been made
+// for the debounce period.  Files are polled rather than watched with filesystem notifications, so that
+// changes are detected the same way on every platform and for editors that replace files when saving.
+//
+// Directories and glob patterns are watched recursively, so files that are created in them are reported
+// as well.  Ignored directories are not read at all, which keeps polling large trees cheap as long as
+// directories such as `node_modules` are ignored.
+type Watcher struct {
+	interval time.Duration
+	debounce time.Duration
+	handler  ChangeHandler
+	base     string
+	files    []string
+	dirs     []watchedDir
+	ignored  []glob.Glob
+	states   map[string]fileState
+	pending  map[string]bool
+	changeAt time.Time
+	stop     chan struct{}
+	lock     sync.Mutex
+}
+
+// New returns a watcher that checks its files every `interval`, and calls `handler` once `debounce` has
+// elapsed since the most recent change.
+func New(interval time.Duration, debounce time.Duration, handler ChangeHandler) *Watcher {
+	return &Watcher{
+		interval: interval,
+		debounce: debounce,
+		handler:  handler,
+		states:   map[string]fileState{},
+		pending:  map[string]bool{},
+	}
+}
+
+// SetFiles replaces the files being watched.
+func (w *Watcher) SetFiles(files []string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.files = []string{}
+
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+
+		w.files = append(w.files, file)
+	}
+
+	w.replaceStates(w.scan(w.files, w.dirs, w.ignored))
+}
+
+// SetPaths replaces the files being watched with `paths`, which are relative to `base` unless they are
+// absolute.  Each path is a file, a directory whose files are watched recursively, or a glob pattern such
+// as `src/**/*.php`.  Files that do not exist yet are watched for being created.  Files and directories
+// that match one of the `ignore` patterns are not watched; patterns without a `/` are matched against the
+// name of each file and directory, and other patterns against paths relative to `base`.
+func (w *Watcher) SetPaths(base string, paths []string, ignore []string) error {
+	if abs, err := filepath.Abs(base); err == nil {
+		base = abs
+	}
+
+	ignored := []glob.Glob{}
+	for _, pattern := range ignore {
+		g, err := compilePattern(strings.TrimSuffix(pattern, "/"))
+		if err != nil {
+			return err
+		}
+
+		ignored = append(ignored, g)
+	}
+
+	files := []string{}
+	dirs := []watchedDir{}
+
+	for _, path := range paths {
+		path = filepath.ToSlash(path)
+
+		if !isPattern(path) {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(base, path)
+			}
+
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				dirs = append(dirs, watchedDir{dir: filepath.Clean(path)})
+			} else {
+				files = append(files, filepath.Clean(path))
+			}
+
+			continue
+		}
+
+		// patterns are matched against paths relative to the base directory, and only the directory
+		// before the first wildcard needs to be searched
+		pattern := path
+		if filepath.IsAbs(filepath.FromSlash(pattern)) {
+			if relative, err := filepath.Rel(base, filepath.FromSlash(pattern)); err == nil {
+				pattern = filepath.ToSlash(relative)
+			}
+		}
+
+		g, err := compilePattern(pattern)
+		if err != nil {
+			return err
+		}
+
+		dirs = append(dirs, watchedDir{dir: filepath.Join(base, filepath.FromSlash(staticPrefix(pattern))), match: g})
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.base, w.files, w.dirs, w.ignored = base, files, dirs, ignored
+	w.replaceStates(w.scan(files, dirs, ignored))
+
+	return nil
+}
+
+// SetInterval changes how often the files are checked.  It takes effect the next time the watcher is started.
+func (w *Watcher) SetInterval(interval time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.interval = interval
+}
+
+// WatchesDirectories returns true if any directories or glob patterns are watched, which are read
+// recursively each time the files are checked.
+func (w *Watcher) WatchesDirectories() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return len(w.dirs) > 0
+}
+
+// replaceStates replaces the watched files with the files in `states`.  Files that are already watched keep
+// their current state, so changes that have not been reported yet are still reported.
+func (w *Watcher) replaceStates(states map[string]fileState) {
+	for file := range states {
+		if state, found := w.states[file]; found {
+			states[file] = state
+		}
+	}
+
+	w.states = states
+}
+
+// Files returns the absolute paths of the files being watched, in sorted order.
+func (w *Watcher) Files() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	result := make([]string, 0, len(w.states))
+	for file := range w.states {
+		result = append(result, file)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Start polls the files in the background until Stop is called.
+func (w *Watcher) Start() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.stop != nil {
+		return
+	}
+
+	w.stop = make(chan struct{})
+
+	go w.run(w.stop, w.interval)
+}
+
+// Stop stops polling the files.  Changes that have not been reported yet are discarded.
+func (w *Watcher) Stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+}
+
+func (w *Watcher) run(stop chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if changed := w.poll(now); len(changed) > 0 {
+				w.handler(changed)
+			}
+		}
+	}
+}
+
+// poll checks each file for changes, and returns the changed files once the debounce period has elapsed.
+func (w *Watcher) poll(now time.Time) []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	current := w.scan(w.files, w.dirs, w.ignored)
+
+	for file, state := range current {
+		if previous, found := w.states[file]; found && previous != state || !found && state.exists {
+			w.pending[file] = true
+			w.changeAt = now
+		}
+	}
+
+	// files that were in a watched directory and have been removed
+	for file, previous := range w.states {
+		if _, found := current[file]; !found && previous.exists {
+			w.pending[file] = true
+			w.changeAt = now
+		}
+	}
+
+	w.states = current
+
+	if len(w.pending) == 0 || now.Sub(w.changeAt) < w.debounce {
+		return nil
+	}
+
+	result := make([]string, 0, len(w.pending))
+	for file := range w.pending {
+		result = append(result, file)
+	}
+
+	sort.Strings(result)
+	w.pending = map[string]bool{}
+
+	return result
+}
+
+// scan returns the current state of `files` and of the files in `dirs` that are not ignored.
+func (w *Watcher) scan(files []string, dirs []watchedDir, ignored []glob.Glob) map[string]fileState {
+	result := map[string]fileState{}
+
+	for _, file := range files {
+		result[file] = readState(file)
+	}
+
+	for _, dir := range dirs {
+		filepath.WalkDir(dir.dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			relative := w.relativePath(path)
+
+			if entry.IsDir() {
+				if path != dir.dir && isIgnored(ignored, relative) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if isIgnored(ignored, relative) || dir.match != nil && !dir.match.Match(relative) {
+				return nil
+			}
+
+			if info, err := entry.Info(); err == nil {
+				result[path] = fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+			}
+
+			return nil
+		})
+	}
+
+	return result
+}
+
+// relativePath returns `path` relative to the base directory, using `/` as the separator.
+func (w *Watcher) relativePath(path string) string {
+	if w.base == "" {
+		return filepath.ToSlash(path)
+	}
+
+	relative, err := filepath.Rel(w.base, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(relative)
+}
+
+func isIgnored(ignored []glob.Glob, relative string) bool {
+	name := relative[strings.LastIndex(relative, "/")+1:]
+
+	for _, g := range ignored {
+		if g.Match(relative) || g.Match(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidatePattern returns an error if `pattern` is not a valid path or ignore pattern.
+func ValidatePattern(pattern string) error {
+	_, err := compilePattern(pattern)
+
+	return err
+}
+
+func compilePattern(pattern string) (glob.Glob, error) {
+	return glob.Compile(filepath.ToSlash(pattern), '/')
+}
+
+func isPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[{")
+}
+
+// staticPrefix returns the directories of `pattern` that come before its first wildcard.
+func staticPrefix(pattern string) string {
+	parts := strings.Split(pattern, "/")
+
+	for i, part := range parts {
+		if isPattern(part) {
+			return strings.Join(parts[:i], "/")
+		}
+	}
+
+	return pattern
+}
+
+func readState(file string) fileState {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileState{}
+	}
+
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
@@ -0,0 +1,116 @@
+package watcher_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackup-app/stackup/lib/watcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcherReportsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "stackup.yaml")
+	include := filepath.Join(dir, "include.yaml")
+
+	os.WriteFile(config, []byte("name: test\n"), 0644)
+	os.WriteFile(include, []byte("tasks: []\n"), 0644)
+
+	var lock sync.Mutex
+	reports := [][]string{}
+
+	w := watcher.New(10*time.Millisecond, 50*time.Millisecond, func(changed []string) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		reports = append(reports, changed)
+	})
+	w.SetFiles([]string{config, include})
+	assert.False(t, w.WatchesDirectories())
+	w.Start()
+	defer w.Stop()
+
+	assert.Equal(t, []string{include, config}, w.Files())
+
+	// both changes are reported together, since the second is made within the debounce period
+	os.WriteFile(config, []byte("name: changed\n"), 0644)
+	time.Sleep(20 * time.Millisecond)
+	os.Remove(include)
+
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return len(reports) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.Equal(t, [][]string{{include, config}}, reports)
+}
+
+func TestWatcherWatchesDirectoriesAndPatterns(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"src/app/models", "node_modules/lib", "docs"} {
+		os.MkdirAll(filepath.Join(dir, name), 0755)
+	}
+
+	os.WriteFile(filepath.Join(dir, "src/app/main.php"), []byte("<?php\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "node_modules/lib/index.js"), []byte("\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "docs/index.md"), []byte("# docs\n"), 0644)
+
+	changes := make(chan []string, 10)
+
+	w := watcher.New(10*time.Millisecond, 30*time.Millisecond, func(changed []string) {
+		changes <- changed
+	})
+
+	err := w.SetPaths(dir, []string{".", "docs/**/*.md", ".env"}, []string{"node_modules", "*.log"})
+	assert.NoError(t, err)
+	assert.True(t, w.WatchesDirectories())
+
+	w.Start()
+	defer w.Stop()
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, ".env"),
+		filepath.Join(dir, "docs/index.md"),
+		filepath.Join(dir, "src/app/main.php"),
+	}, w.Files())
+
+	// ignored files are not reported, and created files are reported
+	os.WriteFile(filepath.Join(dir, "node_modules/lib/index.js"), []byte("changed\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "src/app/debug.log"), []byte("log\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "src/app/models/User.php"), []byte("<?php\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("APP_ENV=local\n"), 0644)
+
+	select {
+	case changed := <-changes:
+		assert.Equal(t, []string{filepath.Join(dir, ".env"), filepath.Join(dir, "src/app/models/User.php")}, changed)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "changes were not reported")
+	}
+
+	os.Remove(filepath.Join(dir, "src/app/main.php"))
+
+	select {
+	case changed := <-changes:
+		assert.Equal(t, []string{filepath.Join(dir, "src/app/main.php")}, changed)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the removed file was not reported")
+	}
+}
+
+func TestWatcherRejectsInvalidPatterns(t *testing.T) {
+	w := watcher.New(time.Second, time.Second, func(changed []string) {})
+
+	assert.Error(t, w.SetPaths(t.TempDir(), []string{"src/[a"}, []string{}))
+	assert.Error(t, w.SetPaths(t.TempDir(), []string{"src"}, []string{"[a"}))
+}